@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/auth"
+	"github.com/google/uuid"
+)
+
+const (
+	accountStatusActive    = "active"
+	accountStatusSuspended = "suspended"
+	accountStatusBanned    = "banned"
+)
+
+const maxAccountStatusReasonLength = 500
+
+// isAccountRestricted reports whether an account is currently locked out.
+// Suspensions lapse on their own once they expire, without anything
+// having to reset the stored status.
+func isAccountRestricted(status string, expiresAt sql.NullTime, now time.Time) bool {
+	if status == accountStatusActive {
+		return false
+	}
+
+	return !expiresAt.Valid || expiresAt.Time.After(now)
+}
+
+// accountRestriction returns an error explaining why the account may not be
+// used, or nil if it may.
+func accountRestriction(status, reason string, expiresAt sql.NullTime, now time.Time) error {
+	if !isAccountRestricted(status, expiresAt, now) {
+		return nil
+	}
+
+	message := fmt.Sprintf("Account is %s", status)
+	if expiresAt.Valid {
+		message += fmt.Sprintf(" until %s", expiresAt.Time.Format(time.RFC3339))
+	}
+	if reason != "" {
+		message += fmt.Sprintf(": %s", reason)
+	}
+
+	return errors.New(message)
+}
+
+func (cfg *apiConfig) checkAccountActive(ctx context.Context, userID uuid.UUID) error {
+	status, err := cfg.database.GetUserAccountStatus(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("Error getting user by ID: %w", err)
+	}
+
+	return accountRestriction(status.AccountStatus, status.StatusReason, status.StatusExpiresAt, time.Now().UTC())
+}
+
+// validateAccessToken checks the token like auth.ValidateJWT and then
+// that its user may still use their account, so a suspension takes effect
+// without waiting for outstanding tokens to expire.
+func (cfg *apiConfig) validateAccessToken(ctx context.Context, accessToken string) (uuid.UUID, error) {
+	userID, err := auth.ValidateJWT(accessToken, cfg.secret)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = cfg.checkAccountActive(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return userID, nil
+}
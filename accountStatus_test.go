@@ -0,0 +1,74 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestAccountRestriction(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	type Case struct {
+		name           string
+		status         string
+		reason         string
+		expiresAt      sql.NullTime
+		wantRestricted bool
+		wantErr        string
+	}
+
+	cases := []Case{
+		{
+			name:   "Active",
+			status: accountStatusActive,
+		},
+		{
+			name:      "Lapsed suspension",
+			status:    accountStatusSuspended,
+			reason:    "spam",
+			expiresAt: sql.NullTime{Time: now.Add(-time.Minute), Valid: true},
+		},
+		{
+			name:           "Suspension ending later",
+			status:         accountStatusSuspended,
+			reason:         "spam",
+			expiresAt:      sql.NullTime{Time: now.Add(time.Hour), Valid: true},
+			wantRestricted: true,
+			wantErr:        "Account is suspended until 2025-06-01T13:00:00Z: spam",
+		},
+		{
+			name:           "Indefinite suspension",
+			status:         accountStatusSuspended,
+			wantRestricted: true,
+			wantErr:        "Account is suspended",
+		},
+		{
+			name:           "Ban",
+			status:         accountStatusBanned,
+			reason:         "abuse",
+			wantRestricted: true,
+			wantErr:        "Account is banned: abuse",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			restricted := isAccountRestricted(c.status, c.expiresAt, now)
+			if restricted != c.wantRestricted {
+				t.Errorf("isAccountRestricted() = %v, want %v", restricted, c.wantRestricted)
+			}
+
+			err := accountRestriction(c.status, c.reason, c.expiresAt, now)
+			if c.wantErr == "" {
+				if err != nil {
+					t.Errorf("accountRestriction() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != c.wantErr {
+				t.Errorf("accountRestriction() error = %v, want %q", err, c.wantErr)
+			}
+		})
+	}
+}
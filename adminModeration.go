@@ -0,0 +1,770 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/auth"
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	moderationActionTriageReport  = "triage_report"
+	moderationActionResolveReport = "resolve_report"
+	moderationActionDismissReport = "dismiss_report"
+	moderationActionHideChirp     = "hide_chirp"
+	moderationActionUnhideChirp   = "unhide_chirp"
+	moderationActionSuspendUser   = "suspend_user"
+	moderationActionUnsuspendUser = "unsuspend_user"
+	moderationActionBanUser       = "ban_user"
+	moderationActionUnbanUser     = "unban_user"
+)
+
+const maxModerationNoteLength = 1000
+
+// reportStatusActions maps the statuses an admin may move a report to onto
+// the action recorded for it.
+var reportStatusActions = map[string]string{
+	"triaged":   moderationActionTriageReport,
+	"resolved":  moderationActionResolveReport,
+	"dismissed": moderationActionDismissReport,
+}
+
+var reportStatuses = map[string]bool{
+	"open":      true,
+	"triaged":   true,
+	"resolved":  true,
+	"dismissed": true,
+}
+
+func isClosedReportStatus(status string) bool {
+	return status == "resolved" || status == "dismissed"
+}
+
+// reportTransition returns the action recorded for moving a report from
+// status to next. On failure it returns the status code to respond with.
+func reportTransition(status, next string) (string, int, error) {
+	action, ok := reportStatusActions[next]
+	if !ok {
+		return "", http.StatusBadRequest, errors.New("Status must be one of triaged, resolved or dismissed")
+	}
+	if isClosedReportStatus(status) {
+		return "", http.StatusConflict, fmt.Errorf("Report has already been %s", status)
+	}
+	if status == next {
+		return "", http.StatusConflict, fmt.Errorf("Report is already %s", status)
+	}
+
+	return action, 0, nil
+}
+
+// authenticateAdmin returns the ID of the admin making the request. On
+// failure it returns the status code to respond with.
+func (cfg *apiConfig) authenticateAdmin(r *http.Request) (uuid.UUID, int, error) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return uuid.Nil, http.StatusUnauthorized, fmt.Errorf("Error getting bearer token: %w", err)
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		return uuid.Nil, http.StatusUnauthorized, fmt.Errorf("Error validating access token: %w", err)
+	}
+
+	user, err := cfg.database.GetUserById(r.Context(), userID)
+	if err != nil {
+		return uuid.Nil, http.StatusUnauthorized, fmt.Errorf("Error getting user by ID: %w", err)
+	}
+	if !user.IsAdmin {
+		return uuid.Nil, http.StatusForbidden, errors.New("Only admins can moderate")
+	}
+
+	return user.ID, 0, nil
+}
+
+func databaseModerationActionToResponse(action database.ModerationAction) ModerationActionResponseBody {
+	out := ModerationActionResponseBody{
+		ID:           action.ID,
+		CreatedAt:    action.CreatedAt,
+		AdminID:      uuidPointer(action.AdminID),
+		Action:       action.Action,
+		ReportID:     uuidPointer(action.ReportID),
+		ChirpID:      uuidPointer(action.ChirpID),
+		TargetUserID: uuidPointer(action.TargetUserID),
+		Note:         action.Note,
+		Reason:       action.Reason,
+	}
+	if action.ExpiresAt.Valid {
+		out.ExpiresAt = &action.ExpiresAt.Time
+	}
+
+	return out
+}
+
+// reportsToResponses attaches each report's chirp. Admins see the stored
+// content even when the chirp is hidden or deleted.
+func (cfg *apiConfig) reportsToResponses(ctx context.Context, reports []database.Report) ([]ReportResponseBody, error) {
+	chirpIDs := make([]uuid.UUID, 0, len(reports))
+	for _, report := range reports {
+		chirpIDs = append(chirpIDs, report.ChirpID)
+	}
+
+	chirps, err := cfg.database.GetChirpsByIds(ctx, chirpIDs)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting reported chirps: %w", err)
+	}
+	chirpsByID := make(map[uuid.UUID]database.Chirp, len(chirps))
+	for _, chirp := range chirps {
+		chirpsByID[chirp.ID] = chirp
+	}
+
+	out := make([]ReportResponseBody, 0, len(reports))
+	for _, report := range reports {
+		response := databaseReportToResponse(report)
+		if chirp, ok := chirpsByID[report.ChirpID]; ok {
+			chirpResponse := databaseChirpToResponse(chirp)
+			response.Chirp = &chirpResponse
+		}
+		out = append(out, response)
+	}
+
+	return out, nil
+}
+
+func parseModerationActionRequest(r *http.Request) (ModerationActionRequestBody, error) {
+	defer r.Body.Close()
+
+	params := ModerationActionRequestBody{}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return params, fmt.Errorf("Error reading request body: %w", err)
+	}
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &params)
+		if err != nil {
+			return params, fmt.Errorf("Error unmarshalling data: %w", err)
+		}
+	}
+
+	params.Note = strings.TrimSpace(params.Note)
+	if len(params.Note) > maxModerationNoteLength {
+		return params, fmt.Errorf("Notes must be at most %d characters long", maxModerationNoteLength)
+	}
+	params.Reason = strings.TrimSpace(params.Reason)
+	if len(params.Reason) > maxAccountStatusReasonLength {
+		return params, fmt.Errorf("Reasons must be at most %d characters long", maxAccountStatusReasonLength)
+	}
+
+	return params, nil
+}
+
+func (cfg *apiConfig) handlerAdminGetReports(w http.ResponseWriter, r *http.Request) {
+	_, code, err := cfg.authenticateAdmin(r)
+	if err != nil {
+		respondWithError(w, code, err.Error())
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing page parameters: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	// The queue shows unfinished reports, oldest first, unless asked
+	// for specific statuses.
+	statuses := []string{"open", "triaged"}
+	if rawStatus := r.URL.Query().Get("status"); rawStatus != "" {
+		statuses = strings.Split(rawStatus, ",")
+		for _, status := range statuses {
+			if !reportStatuses[status] {
+				errMsg := fmt.Sprintf("Unknown report status %q", status)
+				respondWithError(w, http.StatusBadRequest, errMsg)
+				return
+			}
+		}
+	}
+
+	reports, err := cfg.database.GetReportsPage(r.Context(), database.GetReportsPageParams{
+		Statuses:        statuses,
+		CursorCreatedAt: page.cursorCreatedAt,
+		CursorID:        page.cursorID,
+		PageLimit:       page.limit + 1,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting reports: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	nextCursor := ""
+	if len(reports) > int(page.limit) {
+		reports = reports[:page.limit]
+		last := reports[len(reports)-1]
+		nextCursor = nextPageCursor(last.CreatedAt, last.ID)
+	}
+
+	out, err := cfg.reportsToResponses(r.Context(), reports)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ReportsPageResponseBody{
+		Reports:    out,
+		NextCursor: nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerAdminGetReport(w http.ResponseWriter, r *http.Request) {
+	_, code, err := cfg.authenticateAdmin(r)
+	if err != nil {
+		respondWithError(w, code, err.Error())
+		return
+	}
+
+	reportID, err := uuid.Parse(r.PathValue("reportID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	report, err := cfg.database.GetReportById(r.Context(), reportID)
+	if err != nil {
+		errMsg := "Error getting report by ID: Report not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	out, err := cfg.reportsToResponses(r.Context(), []database.Report{report})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, out[0])
+}
+
+func (cfg *apiConfig) handlerAdminUpdateReport(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	adminID, code, err := cfg.authenticateAdmin(r)
+	if err != nil {
+		respondWithError(w, code, err.Error())
+		return
+	}
+
+	reportID, err := uuid.Parse(r.PathValue("reportID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error reading request body: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	params := ReportStatusRequestBody{}
+	err = json.Unmarshal(data, &params)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error unmarshalling data: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	params.Note = strings.TrimSpace(params.Note)
+	if len(params.Note) > maxModerationNoteLength {
+		errMsg := fmt.Sprintf("Notes must be at most %d characters long", maxModerationNoteLength)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error starting transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	report, err := qtx.GetReportByIdForUpdate(r.Context(), reportID)
+	if err != nil {
+		errMsg := "Error getting report by ID: Report not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	action, code, err := reportTransition(report.Status, params.Status)
+	if err != nil {
+		respondWithError(w, code, err.Error())
+		return
+	}
+
+	adminNullID := uuid.NullUUID{UUID: adminID, Valid: true}
+	report, err = qtx.UpdateReportStatus(r.Context(), database.UpdateReportStatusParams{
+		Status:    params.Status,
+		HandledBy: adminNullID,
+		ID:        report.ID,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error updating report: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	_, err = qtx.CreateModerationAction(r.Context(), database.CreateModerationActionParams{
+		AdminID:  adminNullID,
+		Action:   action,
+		ReportID: uuid.NullUUID{UUID: report.ID, Valid: true},
+		ChirpID:  uuid.NullUUID{UUID: report.ChirpID, Valid: true},
+		Note:     params.Note,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error recording moderation action: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		errMsg := fmt.Sprintf("Error committing transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseReportToResponse(report))
+}
+
+func (cfg *apiConfig) handlerAdminHideChirp(w http.ResponseWriter, r *http.Request) {
+	cfg.setChirpHidden(w, r, true)
+}
+
+func (cfg *apiConfig) handlerAdminUnhideChirp(w http.ResponseWriter, r *http.Request) {
+	cfg.setChirpHidden(w, r, false)
+}
+
+// setChirpHidden hides or unhides a chirp and records the action. Hiding
+// also resolves every open report against the chirp.
+func (cfg *apiConfig) setChirpHidden(w http.ResponseWriter, r *http.Request, hide bool) {
+	adminID, code, err := cfg.authenticateAdmin(r)
+	if err != nil {
+		respondWithError(w, code, err.Error())
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	params, err := parseModerationActionRequest(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	chirp, err := cfg.database.GetChirpById(r.Context(), chirpID)
+	if err != nil {
+		errMsg := "Error getting chirp by ID: Chirp not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	if chirp.RechirpOf.Valid {
+		errMsg := "Rechirps have no content of their own; moderate the original chirp"
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error starting transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+	adminNullID := uuid.NullUUID{UUID: adminID, Valid: true}
+
+	// Hiding resolves the cited report below, so it must still be open.
+	if params.ReportID != nil {
+		report, err := qtx.GetReportByIdForUpdate(r.Context(), *params.ReportID)
+		if err != nil || report.ChirpID != chirp.ID {
+			errMsg := "Report not found for this chirp"
+			respondWithError(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		if hide && isClosedReportStatus(report.Status) {
+			errMsg := fmt.Sprintf("Report has already been %s", report.Status)
+			respondWithError(w, http.StatusConflict, errMsg)
+			return
+		}
+	}
+
+	var changed int64
+	action := moderationActionHideChirp
+	if hide {
+		changed, err = qtx.HideChirp(r.Context(), chirp.ID)
+	} else {
+		action = moderationActionUnhideChirp
+		changed, err = qtx.UnhideChirp(r.Context(), chirp.ID)
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("Error updating chirp: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	if changed == 0 {
+		errMsg := "Chirp is already hidden"
+		if !hide {
+			errMsg = "Chirp is not hidden"
+		}
+		respondWithError(w, http.StatusConflict, errMsg)
+		return
+	}
+
+	if hide {
+		_, err = qtx.ResolveOpenReportsForChirp(r.Context(), database.ResolveOpenReportsForChirpParams{
+			HandledBy: adminNullID,
+			ChirpID:   chirp.ID,
+		})
+		if err != nil {
+			errMsg := fmt.Sprintf("Error resolving reports: %v", err)
+			respondWithError(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+	}
+
+	recorded, err := qtx.CreateModerationAction(r.Context(), database.CreateModerationActionParams{
+		AdminID:      adminNullID,
+		Action:       action,
+		ReportID:     optionalUUID(params.ReportID),
+		ChirpID:      uuid.NullUUID{UUID: chirp.ID, Valid: true},
+		TargetUserID: uuid.NullUUID{UUID: chirp.UserID, Valid: true},
+		Note:         params.Note,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error recording moderation action: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		errMsg := fmt.Sprintf("Error committing transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	status := http.StatusOK
+	if hide {
+		status = http.StatusCreated
+	}
+	respondWithJSON(w, status, databaseModerationActionToResponse(recorded))
+}
+
+func (cfg *apiConfig) handlerAdminSuspendUser(w http.ResponseWriter, r *http.Request) {
+	cfg.setUserAccountStatus(w, r, moderationActionSuspendUser)
+}
+
+func (cfg *apiConfig) handlerAdminUnsuspendUser(w http.ResponseWriter, r *http.Request) {
+	cfg.setUserAccountStatus(w, r, moderationActionUnsuspendUser)
+}
+
+func (cfg *apiConfig) handlerAdminBanUser(w http.ResponseWriter, r *http.Request) {
+	cfg.setUserAccountStatus(w, r, moderationActionBanUser)
+}
+
+func (cfg *apiConfig) handlerAdminUnbanUser(w http.ResponseWriter, r *http.Request) {
+	cfg.setUserAccountStatus(w, r, moderationActionUnbanUser)
+}
+
+// setUserAccountStatus suspends, bans, or lifts either. Suspending or
+// banning also revokes every refresh token, so the user is signed out
+// everywhere once their current access token is rejected, and resolves
+// the cited report. Banning cancels their scheduled chirps.
+func (cfg *apiConfig) setUserAccountStatus(w http.ResponseWriter, r *http.Request, action string) {
+	adminID, code, err := cfg.authenticateAdmin(r)
+	if err != nil {
+		respondWithError(w, code, err.Error())
+		return
+	}
+
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	params, err := parseModerationActionRequest(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	now := time.Now().UTC()
+	restricting := action == moderationActionSuspendUser || action == moderationActionBanUser
+	if restricting && params.Reason == "" {
+		errMsg := "A reason is required to suspend or ban a user"
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	expiresAt := sql.NullTime{}
+	if params.ExpiresAt != nil {
+		if action != moderationActionSuspendUser {
+			errMsg := "Only suspensions can have an expiry"
+			respondWithError(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		if !params.ExpiresAt.After(now) {
+			errMsg := "Suspensions must expire in the future"
+			respondWithError(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		expiresAt = sql.NullTime{Time: params.ExpiresAt.UTC(), Valid: true}
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error starting transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	user, err := qtx.GetUserByIdForUpdate(r.Context(), userID)
+	if err != nil {
+		errMsg := "Error getting user by ID: User not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	if restricting && user.IsAdmin {
+		errMsg := "Admins cannot be suspended or banned"
+		respondWithError(w, http.StatusForbidden, errMsg)
+		return
+	}
+
+	// The cited report must be about one of the user's chirps. Suspending
+	// or banning resolves it, so it must still be open.
+	if params.ReportID != nil {
+		report, err := qtx.GetReportByIdForUpdate(r.Context(), *params.ReportID)
+		if err != nil {
+			errMsg := "Error getting report by ID: Report not found"
+			respondWithError(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		reported, err := qtx.GetChirpById(r.Context(), report.ChirpID)
+		if err != nil || reported.UserID != user.ID {
+			errMsg := "Report is not about this user's chirps"
+			respondWithError(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		if restricting && isClosedReportStatus(report.Status) {
+			errMsg := fmt.Sprintf("Report has already been %s", report.Status)
+			respondWithError(w, http.StatusConflict, errMsg)
+			return
+		}
+	}
+
+	// A lapsed suspension counts as active even though the stored status
+	// still says suspended.
+	restricted := isAccountRestricted(user.AccountStatus, user.StatusExpiresAt, now)
+	newStatus := accountStatusActive
+	conflict := ""
+	switch action {
+	case moderationActionSuspendUser:
+		newStatus = accountStatusSuspended
+		if restricted {
+			conflict = fmt.Sprintf("User is already %s", user.AccountStatus)
+		}
+	case moderationActionBanUser:
+		newStatus = accountStatusBanned
+		if user.AccountStatus == accountStatusBanned {
+			conflict = "User is already banned"
+		}
+	case moderationActionUnsuspendUser:
+		if !restricted || user.AccountStatus != accountStatusSuspended {
+			conflict = "User is not suspended"
+		}
+	case moderationActionUnbanUser:
+		if user.AccountStatus != accountStatusBanned {
+			conflict = "User is not banned"
+		}
+	}
+	if conflict != "" {
+		respondWithError(w, http.StatusConflict, conflict)
+		return
+	}
+
+	statusReason := ""
+	if restricting {
+		statusReason = params.Reason
+	}
+	err = qtx.SetUserAccountStatus(r.Context(), database.SetUserAccountStatusParams{
+		AccountStatus:   newStatus,
+		StatusReason:    statusReason,
+		StatusExpiresAt: expiresAt,
+		ID:              user.ID,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error updating user: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	if restricting {
+		err = qtx.RevokeUserRefreshTokens(r.Context(), user.ID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error revoking refresh tokens: %v", err)
+			respondWithError(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+	}
+
+	if restricting && params.ReportID != nil {
+		_, err = qtx.UpdateReportStatus(r.Context(), database.UpdateReportStatusParams{
+			Status:    "resolved",
+			HandledBy: uuid.NullUUID{UUID: adminID, Valid: true},
+			ID:        *params.ReportID,
+		})
+		if err != nil {
+			errMsg := fmt.Sprintf("Error resolving report: %v", err)
+			respondWithError(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+	}
+
+	// A ban is permanent, so chirps still waiting to be published are
+	// cancelled. Suspended users' chirps fail if they come due meanwhile.
+	if action == moderationActionBanUser {
+		err = qtx.DeletePendingScheduledChirps(r.Context(), user.ID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error cancelling scheduled chirps: %v", err)
+			respondWithError(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+	}
+
+	recorded, err := qtx.CreateModerationAction(r.Context(), database.CreateModerationActionParams{
+		AdminID:      uuid.NullUUID{UUID: adminID, Valid: true},
+		Action:       action,
+		ReportID:     optionalUUID(params.ReportID),
+		TargetUserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		Note:         params.Note,
+		Reason:       params.Reason,
+		ExpiresAt:    expiresAt,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error recording moderation action: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		errMsg := fmt.Sprintf("Error committing transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	// Revoking refresh tokens does not end connections that are already
+	// open, so they are told to close.
+	if restricting {
+		cfg.publishEvent(cfg.userStream, streamEventAccountRestricted, []string{accountTopic(user.ID)}, nil)
+	}
+
+	status := http.StatusOK
+	if restricting {
+		status = http.StatusCreated
+	}
+	respondWithJSON(w, status, databaseModerationActionToResponse(recorded))
+}
+
+func (cfg *apiConfig) handlerAdminGetModerationActions(w http.ResponseWriter, r *http.Request) {
+	_, code, err := cfg.authenticateAdmin(r)
+	if err != nil {
+		respondWithError(w, code, err.Error())
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing page parameters: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	query := r.URL.Query()
+
+	chirpID := uuid.NullUUID{}
+	if rawChirpID := query.Get("chirp_id"); rawChirpID != "" {
+		id, err := uuid.Parse(rawChirpID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error parsing chirp_id: %v", err)
+			respondWithError(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		chirpID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	targetUserID := uuid.NullUUID{}
+	if rawUserID := query.Get("user_id"); rawUserID != "" {
+		id, err := uuid.Parse(rawUserID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error parsing user_id: %v", err)
+			respondWithError(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		targetUserID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	actions, err := cfg.database.GetModerationActionsPage(r.Context(), database.GetModerationActionsPageParams{
+		ChirpID:         chirpID,
+		TargetUserID:    targetUserID,
+		CursorCreatedAt: page.cursorCreatedAt,
+		CursorID:        page.cursorID,
+		PageLimit:       page.limit + 1,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting moderation actions: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	nextCursor := ""
+	if len(actions) > int(page.limit) {
+		actions = actions[:page.limit]
+		last := actions[len(actions)-1]
+		nextCursor = nextPageCursor(last.CreatedAt, last.ID)
+	}
+
+	out := make([]ModerationActionResponseBody, 0, len(actions))
+	for _, action := range actions {
+		out = append(out, databaseModerationActionToResponse(action))
+	}
+
+	respondWithJSON(w, http.StatusOK, ModerationActionsPageResponseBody{
+		Actions:    out,
+		NextCursor: nextCursor,
+	})
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestReportTransition(t *testing.T) {
+	type Case struct {
+		name       string
+		status     string
+		next       string
+		wantAction string
+		wantCode   int
+	}
+
+	cases := []Case{
+		{name: "Triage an open report", status: "open", next: "triaged", wantAction: moderationActionTriageReport},
+		{name: "Resolve an open report", status: "open", next: "resolved", wantAction: moderationActionResolveReport},
+		{name: "Dismiss a triaged report", status: "triaged", next: "dismissed", wantAction: moderationActionDismissReport},
+		{name: "Reports cannot be reopened", status: "triaged", next: "open", wantCode: http.StatusBadRequest},
+		{name: "Unknown status", status: "open", next: "closed", wantCode: http.StatusBadRequest},
+		{name: "Already triaged", status: "triaged", next: "triaged", wantCode: http.StatusConflict},
+		{name: "Resolved reports are closed", status: "resolved", next: "triaged", wantCode: http.StatusConflict},
+		{name: "Dismissed reports are closed", status: "dismissed", next: "resolved", wantCode: http.StatusConflict},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			action, code, err := reportTransition(c.status, c.next)
+			if c.wantCode != 0 {
+				if err == nil || code != c.wantCode {
+					t.Errorf("reportTransition() = %d, %v, want code %d", code, err, c.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("reportTransition() error = %v", err)
+			}
+			if action != c.wantAction {
+				t.Errorf("reportTransition() action = %q, want %q", action, c.wantAction)
+			}
+		})
+	}
+}
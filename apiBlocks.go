@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/delroscol98/chirpy/internal/auth"
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/delroscol98/chirpy/internal/stream"
+	"github.com/google/uuid"
+)
+
+// isBlockedBetween reports whether either user has blocked the other.
+func (cfg *apiConfig) isBlockedBetween(ctx context.Context, userID, otherUserID uuid.UUID) (bool, error) {
+	blocked, err := cfg.database.HasBlockBetween(ctx, database.HasBlockBetweenParams{
+		UserID:       userID,
+		OtherUserIds: []uuid.UUID{otherUserID},
+	})
+	if err != nil {
+		return false, fmt.Errorf("Error checking blocks: %w", err)
+	}
+
+	return blocked, nil
+}
+
+// loadHiddenAuthors returns the users whose chirps userID should not see
+// live: anyone blocked in either direction and anyone userID has muted.
+func (cfg *apiConfig) loadHiddenAuthors(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]bool, error) {
+	ids, err := cfg.database.GetHiddenAuthorIds(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting blocked and muted users: %w", err)
+	}
+
+	hidden := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		hidden[id] = true
+	}
+
+	return hidden, nil
+}
+
+// hidesChirpEvent reports whether a chirp event was written by, or quotes,
+// one of the hidden users.
+func hidesChirpEvent(event stream.Event, hidden map[uuid.UUID]bool) bool {
+	for id := range hidden {
+		if event.HasTopic(userTopic(id)) || event.HasTopic(quoteTopic(id)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cfg *apiConfig) handlerBlockUser(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	blockedID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	if blockedID == userID {
+		errMsg := "Users cannot block themselves"
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	_, err = cfg.database.GetUserById(r.Context(), blockedID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting user by ID: %v", err)
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error starting transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	err = qtx.BlockUser(r.Context(), database.BlockUserParams{
+		BlockerID: userID,
+		BlockedID: blockedID,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error blocking user: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	// Blocking severs follows in both directions so neither user keeps
+	// seeing the other on their timeline.
+	err = qtx.RemoveFollowsBetween(r.Context(), database.RemoveFollowsBetweenParams{
+		UserID:      userID,
+		OtherUserID: blockedID,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error removing follows: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		errMsg := fmt.Sprintf("Error committing transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	cfg.publishEvent(cfg.userStream, streamEventFollowsChanged, []string{followsTopic(userID), followsTopic(blockedID)}, nil)
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func (cfg *apiConfig) handlerUnblockUser(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	blockedID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	err = cfg.database.UnblockUser(r.Context(), database.UnblockUserParams{
+		BlockerID: userID,
+		BlockedID: blockedID,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error unblocking user: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	cfg.publishEvent(cfg.userStream, streamEventFollowsChanged, []string{followsTopic(userID), followsTopic(blockedID)}, nil)
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func (cfg *apiConfig) handlerMuteUser(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	mutedID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	if mutedID == userID {
+		errMsg := "Users cannot mute themselves"
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	_, err = cfg.database.GetUserById(r.Context(), mutedID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting user by ID: %v", err)
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	err = cfg.database.MuteUser(r.Context(), database.MuteUserParams{
+		MuterID: userID,
+		MutedID: mutedID,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error muting user: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	cfg.publishEvent(cfg.userStream, streamEventFollowsChanged, []string{followsTopic(userID)}, nil)
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func (cfg *apiConfig) handlerUnmuteUser(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	mutedID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	err = cfg.database.UnmuteUser(r.Context(), database.UnmuteUserParams{
+		MuterID: userID,
+		MutedID: mutedID,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error unmuting user: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	cfg.publishEvent(cfg.userStream, streamEventFollowsChanged, []string{followsTopic(userID)}, nil)
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
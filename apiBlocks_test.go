@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/delroscol98/chirpy/internal/stream"
+	"github.com/google/uuid"
+)
+
+func TestHidesChirpEvent(t *testing.T) {
+	author := uuid.New()
+	quoted := uuid.New()
+	other := uuid.New()
+
+	type Case struct {
+		name    string
+		payload any
+		hidden  map[uuid.UUID]bool
+		want    bool
+	}
+
+	cases := []Case{
+		{
+			name:    "Nobody hidden",
+			payload: ChirpResponseBody{},
+			hidden:  map[uuid.UUID]bool{},
+			want:    false,
+		},
+		{
+			name:    "Unrelated user hidden",
+			payload: ChirpResponseBody{QuoteOf: &ChirpResponseBody{UserID: quoted}},
+			hidden:  map[uuid.UUID]bool{other: true},
+			want:    false,
+		},
+		{
+			name:    "Author hidden",
+			payload: ChirpResponseBody{},
+			hidden:  map[uuid.UUID]bool{author: true},
+			want:    true,
+		},
+		{
+			name:    "Quoted author hidden",
+			payload: ChirpResponseBody{QuoteOf: &ChirpResponseBody{UserID: quoted}},
+			hidden:  map[uuid.UUID]bool{quoted: true},
+			want:    true,
+		},
+		{
+			name:    "Deleted chirp from hidden author",
+			payload: ChirpDeletedEventBody{},
+			hidden:  map[uuid.UUID]bool{author: true},
+			want:    true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := &apiConfig{chirpStream: stream.NewMemory(10, 10)}
+			sub, _, _ := cfg.chirpStream.Subscribe(0)
+			defer cfg.chirpStream.Unsubscribe(sub)
+
+			cfg.publishChirpEvent(streamEventChirpCreated, database.Chirp{UserID: author, Body: "hi #go"}, c.payload)
+			event := <-sub.C
+
+			got := hidesChirpEvent(event, c.hidden)
+			if got != c.want {
+				t.Errorf("hidesChirpEvent() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/delroscol98/chirpy/internal/auth"
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerBookmarkChirp(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	chirp, err := cfg.getLiveChirp(r.Context(), chirpID)
+	if err != nil {
+		errMsg := "Error getting chirp by ID: Chirp not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	// Bookmarks are private, so unlike likes they notify no one.
+	err = cfg.database.BookmarkChirp(r.Context(), database.BookmarkChirpParams{
+		UserID:  userID,
+		ChirpID: chirp.ID,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error bookmarking chirp: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func (cfg *apiConfig) handlerUnbookmarkChirp(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	err = cfg.database.UnbookmarkChirp(r.Context(), database.UnbookmarkChirpParams{
+		UserID:  userID,
+		ChirpID: chirpID,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error removing bookmark: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func (cfg *apiConfig) handlerGetBookmarks(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing page parameters: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	rows, err := cfg.database.GetBookmarkedChirpsPage(r.Context(), database.GetBookmarkedChirpsPageParams{
+		UserID:          userID,
+		CursorCreatedAt: page.cursorCreatedAt,
+		CursorID:        page.cursorID,
+		PageLimit:       page.limit + 1,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bookmarked chirps: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	nextCursor := ""
+	if len(rows) > int(page.limit) {
+		rows = rows[:page.limit]
+		last := rows[len(rows)-1]
+		nextCursor = nextPageCursor(last.BookmarkedAt, last.Chirp.ID)
+	}
+
+	chirps := make([]database.Chirp, 0, len(rows))
+	for _, row := range rows {
+		chirps = append(chirps, row.Chirp)
+	}
+
+	out, err := cfg.chirpsToResponses(r.Context(), chirps, userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error building chirp responses: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ChirpsPageResponseBody{
+		Chirps:     out,
+		NextCursor: nextCursor,
+	})
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerGetChirpHistory(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	chirp, err := cfg.database.GetChirpById(r.Context(), chirpID)
+	if err != nil || chirp.DeletedAt.Valid || chirp.HiddenAt.Valid {
+		errMsg := "Error fetching chirp by ID: Chirp not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	revisions, err := cfg.database.GetChirpRevisions(r.Context(), chirp.ID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting chirp revisions: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	out := make([]ChirpRevisionResponseBody, 0, len(revisions))
+	for _, revision := range revisions {
+		out = append(out, ChirpRevisionResponseBody{
+			ID:         revision.ID,
+			ChirpID:    revision.ChirpID,
+			Body:       revision.Body,
+			CreatedAt:  revision.CreatedAt,
+			ReplacedAt: revision.ReplacedAt,
+		})
+	}
+
+	chirpResponse, err := cfg.chirpToResponse(r.Context(), chirp, cfg.viewerID(r))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error building chirp response: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ChirpHistoryResponseBody{
+		Chirp:     chirpResponse,
+		Revisions: out,
+	})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerGetChirpThread(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	chirp, err := cfg.database.GetChirpById(r.Context(), chirpID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error fetching chirp by ID: %v", err)
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	rootID := chirp.ID
+	if chirp.RootID.Valid {
+		rootID = chirp.RootID.UUID
+	}
+
+	chirps, err := cfg.database.GetThreadChirps(r.Context(), rootID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting thread: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	responses, err := cfg.chirpsToResponses(r.Context(), chirps, cfg.viewerID(r))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error building chirp responses: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	nodes := make(map[uuid.UUID]*ChirpThreadResponseBody, len(chirps))
+	for i, threadChirp := range chirps {
+		node := &ChirpThreadResponseBody{
+			ChirpResponseBody: responses[i],
+			Replies:           []*ChirpThreadResponseBody{},
+		}
+		nodes[threadChirp.ID] = node
+	}
+
+	// Chirps arrive oldest first, so replies are appended in posting order.
+	for _, threadChirp := range chirps {
+		if !threadChirp.ParentID.Valid {
+			continue
+		}
+		parent, ok := nodes[threadChirp.ParentID.UUID]
+		if !ok {
+			continue
+		}
+		parent.Replies = append(parent.Replies, nodes[threadChirp.ID])
+		parent.ReplyCount++
+	}
+
+	root, ok := nodes[rootID]
+	if !ok {
+		errMsg := "Thread root not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, root)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestGetChirpThread(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	alice := uuid.New()
+	bob := uuid.New()
+
+	reply := func(id uuid.UUID, parent, root database.Chirp, userID uuid.UUID, body string, minutes int) database.Chirp {
+		return database.Chirp{
+			ID:        id,
+			CreatedAt: start.Add(time.Duration(minutes) * time.Minute),
+			UpdatedAt: start.Add(time.Duration(minutes) * time.Minute),
+			Body:      body,
+			UserID:    userID,
+			ParentID:  uuid.NullUUID{UUID: parent.ID, Valid: true},
+			RootID:    uuid.NullUUID{UUID: root.ID, Valid: true},
+		}
+	}
+
+	// root
+	// ├── first
+	// └── deleted (by bob)
+	//     └── nested
+	root := database.Chirp{ID: uuid.New(), CreatedAt: start, UpdatedAt: start, Body: "root", UserID: alice}
+	first := reply(uuid.New(), root, root, alice, "first", 1)
+	deleted := reply(uuid.New(), root, root, bob, "deleted", 2)
+	deleted.DeletedAt = sql.NullTime{Time: start.Add(time.Hour), Valid: true}
+	nested := reply(uuid.New(), deleted, root, alice, "nested", 3)
+	thread := []database.Chirp{root, first, deleted, nested}
+
+	type Case struct {
+		name            string
+		chirpID         uuid.UUID
+		viewerID        uuid.UUID
+		wantStatus      int
+		wantDeletedBody string
+	}
+
+	cases := []Case{
+		{
+			name:       "From the root",
+			chirpID:    root.ID,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "From a nested reply",
+			chirpID:    nested.ID,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:            "Tombstone author sees the body",
+			chirpID:         root.ID,
+			viewerID:        bob,
+			wantStatus:      http.StatusOK,
+			wantDeletedBody: "deleted",
+		},
+		{
+			name:       "Unknown chirp",
+			chirpID:    uuid.New(),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			fake.stubChirpResponses()
+			fake.on("GetChirpById", func(args []driver.Value) ([][]driver.Value, error) {
+				for _, chirp := range thread {
+					if chirp.ID == argUUID(args, 0) {
+						return [][]driver.Value{modelRow(chirp)}, nil
+					}
+				}
+				return nil, nil
+			})
+			fake.on("GetThreadChirps", func(args []driver.Value) ([][]driver.Value, error) {
+				if argUUID(args, 0) != root.ID {
+					t.Errorf("GetThreadChirps(%v), want the root %v", args[0], root.ID)
+				}
+				rows := [][]driver.Value{}
+				for _, chirp := range thread {
+					rows = append(rows, modelRow(chirp))
+				}
+				return rows, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/chirps/"+c.chirpID.String()+"/thread", nil)
+			if c.viewerID != uuid.Nil {
+				req.Header = authHeader(t, c.viewerID)
+			}
+			req.SetPathValue("chirpID", c.chirpID.String())
+			rec := httptest.NewRecorder()
+			cfg.handlerGetChirpThread(rec, req)
+
+			if rec.Code != c.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, c.wantStatus, rec.Body)
+			}
+			if c.wantStatus != http.StatusOK {
+				return
+			}
+
+			got := ChirpThreadResponseBody{}
+			err := json.Unmarshal(rec.Body.Bytes(), &got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.ID != root.ID || got.ReplyCount != 2 || len(got.Replies) != 2 {
+				t.Fatalf("Root = %v with %d replies, want %v with 2", got.ID, len(got.Replies), root.ID)
+			}
+			if got.Replies[0].ID != first.ID || got.Replies[1].ID != deleted.ID {
+				t.Errorf("Replies = %v, %v, want %v, %v in posting order", got.Replies[0].ID, got.Replies[1].ID, first.ID, deleted.ID)
+			}
+
+			tombstone := got.Replies[1]
+			if !tombstone.Deleted || tombstone.Body != c.wantDeletedBody {
+				t.Errorf("Tombstone deleted = %v, body = %q, want true, %q", tombstone.Deleted, tombstone.Body, c.wantDeletedBody)
+			}
+			if len(tombstone.Replies) != 1 || tombstone.Replies[0].ID != nested.ID {
+				t.Errorf("Tombstone lost its reply %v", nested.ID)
+			}
+		})
+	}
+}
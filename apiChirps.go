@@ -1,17 +1,44 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"sort"
+	"time"
 
 	"github.com/delroscol98/chirpy/internal/auth"
 	"github.com/delroscol98/chirpy/internal/database"
 	"github.com/google/uuid"
 )
 
+func (cfg *apiConfig) getLiveChirp(ctx context.Context, chirpID uuid.UUID) (database.Chirp, error) {
+	chirp, err := cfg.database.GetChirpById(ctx, chirpID)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+
+	if chirp.RechirpOf.Valid {
+		chirp, err = cfg.database.GetChirpById(ctx, chirp.RechirpOf.UUID)
+		if err != nil {
+			return database.Chirp{}, err
+		}
+	}
+
+	if chirp.DeletedAt.Valid {
+		return database.Chirp{}, errors.New("chirp has been deleted")
+	}
+	if chirp.HiddenAt.Valid {
+		return database.Chirp{}, errors.New("chirp has been hidden by a moderator")
+	}
+
+	return chirp, nil
+}
+
 func (cfg *apiConfig) handlerGetChirpById(w http.ResponseWriter, r *http.Request) {
 	id, err := uuid.Parse(r.PathValue("chirpID"))
 	if err != nil {
@@ -26,55 +53,145 @@ func (cfg *apiConfig) handlerGetChirpById(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	out := ChirpResponseBody{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserID:    chirp.UserID,
+	if chirp.DeletedAt.Valid {
+		errMsg := "Chirp has been deleted"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	viewerID := cfg.viewerID(r)
+	if chirp.HiddenAt.Valid && chirp.UserID != viewerID {
+		errMsg := "Chirp has been hidden by a moderator"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	out, err := cfg.chirpToResponse(r.Context(), chirp, viewerID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error building chirp response: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
 	}
 
 	respondWithJSON(w, http.StatusOK, out)
 }
 
 func (cfg *apiConfig) handlerGetChirps(w http.ResponseWriter, r *http.Request) {
-	var chirps []database.Chirp
-	authorID, err := uuid.Parse(r.URL.Query().Get("author_id"))
+	query := r.URL.Query()
+
+	page, err := parsePageParams(r)
 	if err != nil {
-		chirps, err = cfg.database.GetAllChirpsAsc(r.Context())
+		errMsg := fmt.Sprintf("Error parsing page parameters: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	authorID := uuid.NullUUID{}
+	if rawAuthorID := query.Get("author_id"); rawAuthorID != "" {
+		id, err := uuid.Parse(rawAuthorID)
 		if err != nil {
-			errMsg := fmt.Sprintf("Error getting chirps: %v", err)
-			respondWithError(w, http.StatusInternalServerError, errMsg)
+			errMsg := fmt.Sprintf("Error parsing author_id: %v", err)
+			respondWithError(w, http.StatusBadRequest, errMsg)
 			return
 		}
-	} else {
-		chirps, err = cfg.database.GetAllChirpsByIdAsc(r.Context(), authorID)
-		if err != nil {
-			errMsg := fmt.Sprintf("Error getting chirps: %v", err)
+		authorID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	viewerID := cfg.viewerID(r)
+
+	// An author's pinned chirp leads the first page and is left out of the
+	// listing on every page so it is never shown twice.
+	var pin *database.Chirp
+	excludeID := uuid.NullUUID{}
+	if authorID.Valid {
+		pinnedChirp, err := cfg.database.GetPinnedChirp(r.Context(), database.GetPinnedChirpParams{
+			AuthorID: authorID.UUID,
+			ViewerID: viewerID,
+		})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			errMsg := fmt.Sprintf("Error getting pinned chirp: %v", err)
 			respondWithError(w, http.StatusInternalServerError, errMsg)
 			return
 		}
+		if err == nil {
+			excludeID = uuid.NullUUID{UUID: pinnedChirp.ID, Valid: true}
+			if !page.cursorCreatedAt.Valid {
+				pin = &pinnedChirp
+			}
+		}
 	}
+	rowLimit := pinnedPageLimit(page.limit, pin != nil)
 
-	sortParam := r.URL.Query().Get("sort")
-	if sortParam == "desc" {
-		sort.Slice(chirps, func(i, j int) bool {
-			return chirps[i].CreatedAt.After(chirps[j].CreatedAt)
+	// One extra row tells us whether another page exists.
+	var chirps []database.Chirp
+	if query.Get("sort") == "desc" {
+		chirps, err = cfg.database.GetChirpsPageDesc(r.Context(), database.GetChirpsPageDescParams{
+			AuthorID:        authorID,
+			ExcludeID:       excludeID,
+			ViewerID:        viewerID,
+			CursorCreatedAt: page.cursorCreatedAt,
+			CursorID:        page.cursorID,
+			PageLimit:       rowLimit + 1,
+		})
+	} else {
+		chirps, err = cfg.database.GetChirpsPageAsc(r.Context(), database.GetChirpsPageAscParams{
+			AuthorID:        authorID,
+			ExcludeID:       excludeID,
+			ViewerID:        viewerID,
+			CursorCreatedAt: page.cursorCreatedAt,
+			CursorID:        page.cursorID,
+			PageLimit:       rowLimit + 1,
 		})
 	}
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting chirps: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
 
-	out := make([]ChirpResponseBody, 0, len(chirps))
-	for _, chirp := range chirps {
-		out = append(out, ChirpResponseBody{
-			ID:        chirp.ID,
-			CreatedAt: chirp.CreatedAt,
-			UpdatedAt: chirp.UpdatedAt,
-			Body:      chirp.Body,
-			UserID:    chirp.UserID,
-		})
+	chirps, nextCursor := pinChirpPage(chirps, pin, rowLimit)
+
+	out, err := cfg.chirpsToResponses(r.Context(), chirps, viewerID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error building chirp responses: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	if pin != nil {
+		out[0].Pinned = true
 	}
 
-	respondWithJSON(w, http.StatusOK, out)
+	respondWithJSON(w, http.StatusOK, ChirpsPageResponseBody{
+		Chirps:     out,
+		NextCursor: nextCursor,
+	})
+}
+
+// pinnedPageLimit is how many listed chirps fit on a page of limit. The pin
+// takes one of the slots unless it would be the only chirp on the page.
+func pinnedPageLimit(limit int32, pinned bool) int32 {
+	if pinned && limit > 1 {
+		return limit - 1
+	}
+
+	return limit
+}
+
+// pinChirpPage trims rows fetched with one extra row to rowLimit, returns
+// the cursor for the next page and puts pin, if any, in front.
+func pinChirpPage(rows []database.Chirp, pin *database.Chirp, rowLimit int32) ([]database.Chirp, string) {
+	nextCursor := ""
+	if len(rows) > int(rowLimit) {
+		rows = rows[:rowLimit]
+		last := rows[len(rows)-1]
+		nextCursor = nextPageCursor(last.CreatedAt, last.ID)
+	}
+
+	if pin == nil {
+		return rows, nextCursor
+	}
+
+	return append([]database.Chirp{*pin}, rows...), nextCursor
 }
 
 func (cfg *apiConfig) handlerCreateChirp(w http.ResponseWriter, r *http.Request) {
@@ -94,11 +211,6 @@ func (cfg *apiConfig) handlerCreateChirp(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if len(params.Body) > 140 {
-		errMsg := "chirps must be at most 140 characters long"
-		respondWithError(w, http.StatusBadRequest, errMsg)
-		return
-	}
 	token, err := auth.GetBearerToken(r.Header)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
@@ -106,30 +218,191 @@ func (cfg *apiConfig) handlerCreateChirp(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	userID, err := auth.ValidateJWT(token, cfg.secret)
+	userID, err := cfg.validateAccessToken(r.Context(), token)
 	if err != nil {
 		errMsg := "User unauthorized for this action"
 		respondWithError(w, http.StatusUnauthorized, errMsg)
 		return
 	}
 
-	chirp, err := cfg.database.CreateChirp(r.Context(), database.CreateChirpParams{
-		Body:   cleanBody(params.Body),
-		UserID: userID,
-	})
+	if params.PublishAt != nil {
+		cfg.scheduleChirp(w, r, userID, params)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
 	if err != nil {
-		errMsg := fmt.Sprintf("Error creating chirp: %v", err)
+		errMsg := fmt.Sprintf("Error starting transaction: %v", err)
 		respondWithError(w, http.StatusInternalServerError, errMsg)
 		return
 	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+	notifications := notificationBatch{}
 
-	respondWithJSON(w, http.StatusCreated, ChirpResponseBody{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserID:    chirp.UserID,
-	})
+	pending, code, err := cfg.prepareChirp(r.Context(), qtx, userID, params)
+	if err != nil {
+		respondWithError(w, code, err.Error())
+		return
+	}
+
+	chirp, err := cfg.insertChirp(r.Context(), qtx, userID, pending, &notifications)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		errMsg := fmt.Sprintf("Error committing transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	out, err := cfg.announceChirp(r.Context(), chirp, notifications)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, out)
+}
+
+func (cfg *apiConfig) handlerUpdateChirp(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error reading request body: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	// Only the body can be edited, so replies, quotes, media, polls and
+	// schedules are rejected rather than silently dropped.
+	params := ChirpEditRequestBody{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&params)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error unmarshalling data: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	body, err := cfg.validateChirpBody(params.Body)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error starting transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+	notifications := notificationBatch{}
+
+	chirp, err := qtx.GetChirpByIdForUpdate(r.Context(), chirpID)
+	if err != nil || chirp.DeletedAt.Valid {
+		errMsg := "Error getting chirp by ID: Chirp not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	if userID != chirp.UserID {
+		errMsg := "User forbidden for this action"
+		respondWithError(w, http.StatusForbidden, errMsg)
+		return
+	}
+
+	if chirp.HiddenAt.Valid {
+		errMsg := "Chirps hidden by a moderator cannot be edited"
+		respondWithError(w, http.StatusForbidden, errMsg)
+		return
+	}
+
+	if chirp.RechirpOf.Valid {
+		errMsg := "Rechirps cannot be edited"
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	if time.Since(chirp.CreatedAt) > cfg.chirpEditWindow {
+		errMsg := fmt.Sprintf("Chirps can only be edited within %v of being posted", cfg.chirpEditWindow)
+		respondWithError(w, http.StatusForbidden, errMsg)
+		return
+	}
+
+	if body != chirp.Body {
+		_, err = qtx.CreateChirpRevision(r.Context(), database.CreateChirpRevisionParams{
+			ChirpID:   chirp.ID,
+			Body:      chirp.Body,
+			CreatedAt: chirp.UpdatedAt,
+		})
+		if err != nil {
+			errMsg := fmt.Sprintf("Error saving chirp revision: %v", err)
+			respondWithError(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		chirp, err = qtx.UpdateChirpBody(r.Context(), database.UpdateChirpBodyParams{
+			Body: body,
+			ID:   chirp.ID,
+		})
+		if err != nil {
+			errMsg := fmt.Sprintf("Error updating chirp: %v", err)
+			respondWithError(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		err = cfg.indexChirpEntities(r.Context(), qtx, chirp, &notifications)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error indexing chirp: %v", err)
+			respondWithError(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		errMsg := fmt.Sprintf("Error committing transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	cfg.publishNotifications(notifications)
+
+	out, err := cfg.chirpToResponse(r.Context(), chirp, userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error building chirp response: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, out)
 }
 
 func (cfg *apiConfig) handlerDeleteChirpByID(w http.ResponseWriter, r *http.Request) {
@@ -140,7 +413,7 @@ func (cfg *apiConfig) handlerDeleteChirpByID(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	userID, err := auth.ValidateJWT(accessToken, cfg.secret)
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error validating access token: %v", err)
 		respondWithError(w, http.StatusForbidden, errMsg)
@@ -154,9 +427,18 @@ func (cfg *apiConfig) handlerDeleteChirpByID(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	chirp, err := cfg.database.GetChirpById(r.Context(), chirpID)
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
 	if err != nil {
-		errMsg := fmt.Sprintf("Error getting chirp by ID: %v", err)
+		errMsg := fmt.Sprintf("Error starting transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	chirp, err := qtx.GetChirpByIdForUpdate(r.Context(), chirpID)
+	if err != nil || chirp.DeletedAt.Valid {
+		errMsg := "Error getting chirp by ID: Chirp not found"
 		respondWithError(w, http.StatusNotFound, errMsg)
 		return
 	}
@@ -167,15 +449,57 @@ func (cfg *apiConfig) handlerDeleteChirpByID(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	err = cfg.database.DeleteChirpById(r.Context(), database.DeleteChirpByIdParams{
-		ID:     chirpID,
-		UserID: userID,
-	})
+	// Deleted chirps go to the trash along with their rechirps and keep
+	// their content until the retention window passes. Clients show ones
+	// that are replied to or quoted as tombstones so threads and quotes
+	// keep their shape. A rechirp has no content of its own, so deleting
+	// one just undoes it.
+	tombstoned := false
+	if chirp.RechirpOf.Valid {
+		err = qtx.DeleteUserRechirp(r.Context(), database.DeleteUserRechirpParams{
+			UserID:    userID,
+			RechirpOf: chirp.RechirpOf.UUID,
+		})
+	} else {
+		var replyCount, quoteCount int64
+		replyCount, err = qtx.CountChirpReplies(r.Context(), chirpID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error counting chirp replies: %v", err)
+			respondWithError(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		quoteCount, err = qtx.CountChirpQuotes(r.Context(), chirpID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error counting chirp quotes: %v", err)
+			respondWithError(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		tombstoned = replyCount > 0 || quoteCount > 0
+		err = qtx.SoftDeleteChirp(r.Context(), database.SoftDeleteChirpParams{
+			DeletedAt: time.Now().UTC(),
+			ID:        chirpID,
+		})
+	}
 	if err != nil {
-		errMsg := "Error deleting Chirp: Chirp not found"
-		respondWithError(w, http.StatusNotFound, errMsg)
+		errMsg := fmt.Sprintf("Error deleting Chirp: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
 		return
 	}
 
+	err = tx.Commit()
+	if err != nil {
+		errMsg := fmt.Sprintf("Error committing transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	cfg.publishChirpEvent(streamEventChirpDeleted, chirp, ChirpDeletedEventBody{
+		ID:         chirp.ID,
+		UserID:     chirp.UserID,
+		Tombstoned: tombstoned,
+	})
+
 	respondWithJSON(w, http.StatusNoContent, nil)
 }
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/delroscol98/chirpy/internal/pagination"
+	"github.com/google/uuid"
+)
+
+func TestPinnedChirpPages(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	chirps := make([]database.Chirp, 7)
+	for i := range chirps {
+		chirps[i] = database.Chirp{ID: uuid.New(), CreatedAt: start.Add(time.Duration(i) * time.Minute)}
+	}
+
+	// fetch mirrors GetChirpsPageAsc: the pin is excluded and rows come
+	// after the cursor, oldest first.
+	fetch := func(cursor string, excludeID uuid.UUID, limit int32) []database.Chirp {
+		after := time.Time{}
+		if cursor != "" {
+			decoded, err := pagination.DecodeCursor(cursor)
+			if err != nil {
+				t.Fatalf("DecodeCursor() error = %v", err)
+			}
+			after = decoded.CreatedAt
+		}
+
+		rows := []database.Chirp{}
+		for _, chirp := range chirps {
+			if chirp.ID != excludeID && chirp.CreatedAt.After(after) && len(rows) < int(limit) {
+				rows = append(rows, chirp)
+			}
+		}
+		return rows
+	}
+
+	type Case struct {
+		name     string
+		limit    int32
+		pinIndex int
+	}
+
+	cases := []Case{
+		{name: "No pin", limit: 3, pinIndex: -1},
+		{name: "Pin on a later page", limit: 3, pinIndex: 5},
+		{name: "Pin on the first page", limit: 3, pinIndex: 1},
+		{name: "Pin with a page of one", limit: 1, pinIndex: 4},
+		{name: "Page larger than the feed", limit: 20, pinIndex: 6},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			excludeID := uuid.Nil
+			if c.pinIndex >= 0 {
+				excludeID = chirps[c.pinIndex].ID
+			}
+
+			seen := []uuid.UUID{}
+			cursor := ""
+			for page := 0; page == 0 || cursor != ""; page++ {
+				if page > len(chirps) {
+					t.Fatal("Pagination did not terminate")
+				}
+
+				var pin *database.Chirp
+				if page == 0 && c.pinIndex >= 0 {
+					pin = &chirps[c.pinIndex]
+				}
+				rowLimit := pinnedPageLimit(c.limit, pin != nil)
+
+				var rows []database.Chirp
+				rows, cursor = pinChirpPage(fetch(cursor, excludeID, rowLimit+1), pin, rowLimit)
+				if len(rows) > int(c.limit) && c.limit > 1 {
+					t.Errorf("Page %d has %d chirps, want at most %d", page, len(rows), c.limit)
+				}
+				if pin != nil && rows[0].ID != pin.ID {
+					t.Errorf("Page %d starts with %v, want the pin %v", page, rows[0].ID, pin.ID)
+				}
+				for _, row := range rows {
+					if slices.Contains(seen, row.ID) {
+						t.Errorf("Chirp %v listed twice", row.ID)
+					}
+					seen = append(seen, row.ID)
+				}
+			}
+
+			if len(seen) != len(chirps) {
+				t.Errorf("Listed %d chirps, want %d", len(seen), len(chirps))
+			}
+		})
+	}
+}
+
+// fakeChirpStore keeps one chirp and its revisions in memory, answering
+// the queries editing and reading its history make.
+func fakeChirpStore(fake *fakeDB, chirp *database.Chirp) *[]database.ChirpRevision {
+	revisions := &[]database.ChirpRevision{}
+
+	getChirp := func(args []driver.Value) ([][]driver.Value, error) {
+		if argUUID(args, 0) != chirp.ID {
+			return nil, nil
+		}
+		return [][]driver.Value{modelRow(*chirp)}, nil
+	}
+	fake.on("GetChirpById", getChirp)
+	fake.on("GetChirpByIdForUpdate", getChirp)
+	fake.on("UpdateChirpBody", func(args []driver.Value) ([][]driver.Value, error) {
+		chirp.Body = args[0].(string)
+		chirp.UpdatedAt = chirp.UpdatedAt.Add(time.Minute)
+		return [][]driver.Value{modelRow(*chirp)}, nil
+	})
+	fake.on("CreateChirpRevision", func(args []driver.Value) ([][]driver.Value, error) {
+		revision := database.ChirpRevision{
+			ID:         uuid.New(),
+			ChirpID:    argUUID(args, 0),
+			Body:       args[1].(string),
+			CreatedAt:  args[2].(time.Time),
+			ReplacedAt: time.Now(),
+		}
+		*revisions = append(*revisions, revision)
+		return [][]driver.Value{modelRow(revision)}, nil
+	})
+	// Newest first, as GetChirpRevisions orders them.
+	fake.on("GetChirpRevisions", func(args []driver.Value) ([][]driver.Value, error) {
+		rows := [][]driver.Value{}
+		for i := len(*revisions) - 1; i >= 0; i-- {
+			rows = append(rows, modelRow((*revisions)[i]))
+		}
+		return rows, nil
+	})
+
+	return revisions
+}
+
+func updateChirpRequest(t *testing.T, cfg *apiConfig, chirpID uuid.UUID, header http.Header, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/chirps/"+chirpID.String(), strings.NewReader(body))
+	if header != nil {
+		req.Header = header
+	}
+	req.SetPathValue("chirpID", chirpID.String())
+	rec := httptest.NewRecorder()
+	cfg.handlerUpdateChirp(rec, req)
+	return rec
+}
+
+func TestUpdateChirp(t *testing.T) {
+	authorID := uuid.New()
+
+	type Case struct {
+		name          string
+		age           time.Duration
+		rechirp       bool
+		userID        uuid.UUID
+		anonymous     bool
+		request       string
+		wantStatus    int
+		wantBody      string
+		wantRevisions int
+	}
+
+	cases := []Case{
+		{
+			name:          "Edit within the window",
+			age:           5 * time.Minute,
+			userID:        authorID,
+			request:       `{"body":"hello there"}`,
+			wantStatus:    http.StatusOK,
+			wantBody:      "hello there",
+			wantRevisions: 1,
+		},
+		{
+			name:       "Unchanged body keeps no revision",
+			age:        5 * time.Minute,
+			userID:     authorID,
+			request:    `{"body":"hello world"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   "hello world",
+		},
+		{
+			name:       "Edit window closed",
+			age:        16 * time.Minute,
+			userID:     authorID,
+			request:    `{"body":"too late"}`,
+			wantStatus: http.StatusForbidden,
+			wantBody:   "hello world",
+		},
+		{
+			name:       "Another user's chirp",
+			age:        time.Minute,
+			userID:     uuid.New(),
+			request:    `{"body":"not mine"}`,
+			wantStatus: http.StatusForbidden,
+			wantBody:   "hello world",
+		},
+		{
+			name:       "Rechirp",
+			age:        time.Minute,
+			rechirp:    true,
+			userID:     authorID,
+			request:    `{"body":"edited"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "hello world",
+		},
+		{
+			name:       "Unauthenticated with an invalid body",
+			age:        time.Minute,
+			anonymous:  true,
+			request:    `{"body":"` + strings.Repeat("a", maxChirpLength+1) + `"}`,
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   "hello world",
+		},
+		{
+			name:       "Body too long",
+			age:        time.Minute,
+			userID:     authorID,
+			request:    `{"body":"` + strings.Repeat("a", maxChirpLength+1) + `"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "hello world",
+		},
+		{
+			name:       "Fields other than the body",
+			age:        time.Minute,
+			userID:     authorID,
+			request:    `{"body":"edited","poll":{"options":["a","b"]}}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "hello world",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			fake.stubChirpResponses()
+
+			createdAt := time.Now().UTC().Add(-c.age)
+			chirp := database.Chirp{
+				ID:        uuid.New(),
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+				Body:      "hello world",
+				UserID:    authorID,
+			}
+			if c.rechirp {
+				chirp.RechirpOf = uuid.NullUUID{UUID: uuid.New(), Valid: true}
+			}
+			revisions := fakeChirpStore(fake, &chirp)
+
+			var header http.Header
+			if !c.anonymous {
+				header = authHeader(t, c.userID)
+			}
+			rec := updateChirpRequest(t, cfg, chirp.ID, header, c.request)
+
+			if rec.Code != c.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, c.wantStatus, rec.Body)
+			}
+			if chirp.Body != c.wantBody {
+				t.Errorf("Body = %q, want %q", chirp.Body, c.wantBody)
+			}
+			if len(*revisions) != c.wantRevisions {
+				t.Errorf("Got %d revisions, want %d", len(*revisions), c.wantRevisions)
+			}
+		})
+	}
+}
+
+func TestChirpHistory(t *testing.T) {
+	cfg, fake := newTestConfig(t)
+	fake.stubChirpResponses()
+
+	authorID := uuid.New()
+	createdAt := time.Now().UTC().Add(-time.Minute)
+	chirp := database.Chirp{
+		ID:        uuid.New(),
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+		Body:      "first",
+		UserID:    authorID,
+	}
+	fakeChirpStore(fake, &chirp)
+
+	versionTimes := []time.Time{chirp.UpdatedAt}
+	for _, body := range []string{"second", "third"} {
+		rec := updateChirpRequest(t, cfg, chirp.ID, authHeader(t, authorID), `{"body":"`+body+`"}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Edit status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+		}
+		versionTimes = append(versionTimes, chirp.UpdatedAt)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/chirps/"+chirp.ID.String()+"/history", nil)
+	req.SetPathValue("chirpID", chirp.ID.String())
+	rec := httptest.NewRecorder()
+	cfg.handlerGetChirpHistory(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("History status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	history := ChirpHistoryResponseBody{}
+	err := json.Unmarshal(rec.Body.Bytes(), &history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history.Chirp.Body != "third" {
+		t.Errorf("Chirp body = %q, want %q", history.Chirp.Body, "third")
+	}
+
+	// Each revision keeps a replaced body and when that body was written.
+	want := []struct {
+		body      string
+		createdAt time.Time
+	}{
+		{body: "second", createdAt: versionTimes[1]},
+		{body: "first", createdAt: versionTimes[0]},
+	}
+	if len(history.Revisions) != len(want) {
+		t.Fatalf("Got %d revisions, want %d", len(history.Revisions), len(want))
+	}
+	for i, revision := range history.Revisions {
+		if revision.Body != want[i].body || !revision.CreatedAt.Equal(want[i].createdAt) {
+			t.Errorf("Revision %d = %q at %v, want %q at %v", i, revision.Body, revision.CreatedAt, want[i].body, want[i].createdAt)
+		}
+	}
+}
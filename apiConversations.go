@@ -0,0 +1,600 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/delroscol98/chirpy/internal/auth"
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const maxConversationParticipants = 50
+
+func (cfg *apiConfig) handlerGetConversations(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing page parameters: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	// Conversations are ordered by their latest activity, so the cursor
+	// carries updated_at rather than created_at.
+	conversations, err := cfg.database.GetConversationsPage(r.Context(), database.GetConversationsPageParams{
+		UserID:          userID,
+		CursorUpdatedAt: page.cursorCreatedAt,
+		CursorID:        page.cursorID,
+		PageLimit:       page.limit + 1,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting conversations: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	nextCursor := ""
+	if len(conversations) > int(page.limit) {
+		conversations = conversations[:page.limit]
+		last := conversations[len(conversations)-1]
+		nextCursor = nextPageCursor(last.UpdatedAt, last.ID)
+	}
+
+	out, err := cfg.conversationsToResponses(r.Context(), conversations, userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error building conversation responses: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ConversationsPageResponseBody{
+		Conversations: out,
+		NextCursor:    nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerCreateConversation(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error reading request body: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	params := ConversationRequestBody{}
+	err = json.Unmarshal(data, &params)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error unmarshalling data: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	seen := map[uuid.UUID]bool{userID: true}
+	otherIDs := []uuid.UUID{}
+	for _, id := range params.ParticipantIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		otherIDs = append(otherIDs, id)
+	}
+	if len(otherIDs) == 0 {
+		errMsg := "Conversations need at least one other participant"
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+	if len(otherIDs)+1 > maxConversationParticipants {
+		errMsg := fmt.Sprintf("Conversations can have at most %d participants", maxConversationParticipants)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	body := ""
+	if params.Body != "" {
+		body, err = validateDirectMessageBody(params.Body)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	users, err := cfg.database.GetUsersByIds(r.Context(), otherIDs)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting participants: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	if len(users) != len(otherIDs) {
+		errMsg := "One or more participants do not exist"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	// Every participant sees every message, so a block between any two of
+	// them rules the conversation out, not just one involving the creator.
+	blocked, err := cfg.database.HasBlockAmong(r.Context(), append([]uuid.UUID{userID}, otherIDs...))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error checking blocks: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	if blocked {
+		errMsg := "Cannot start a conversation between users who have blocked each other"
+		respondWithError(w, http.StatusForbidden, errMsg)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error starting transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	// A one-to-one conversation is reused rather than duplicated.
+	status := http.StatusCreated
+	conversation := database.Conversation{}
+	if len(otherIDs) == 1 {
+		conversation, err = qtx.FindDirectConversation(r.Context(), database.FindDirectConversationParams{
+			UserID:      userID,
+			OtherUserID: otherIDs[0],
+		})
+		if err == nil {
+			status = http.StatusOK
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			errMsg := fmt.Sprintf("Error finding conversation: %v", err)
+			respondWithError(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+	}
+
+	if status == http.StatusCreated {
+		conversation, err = qtx.CreateConversation(r.Context(), uuid.NullUUID{UUID: userID, Valid: true})
+		if err != nil {
+			errMsg := fmt.Sprintf("Error creating conversation: %v", err)
+			respondWithError(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+
+		for _, participantID := range append([]uuid.UUID{userID}, otherIDs...) {
+			err = qtx.AddConversationParticipant(r.Context(), database.AddConversationParticipantParams{
+				ConversationID: conversation.ID,
+				UserID:         participantID,
+			})
+			if err != nil {
+				errMsg := fmt.Sprintf("Error adding participant: %v", err)
+				respondWithError(w, http.StatusInternalServerError, errMsg)
+				return
+			}
+		}
+	}
+
+	if body != "" {
+		message, err := postDirectMessage(r.Context(), qtx, conversation.ID, userID, body)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		conversation.UpdatedAt = message.CreatedAt
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		errMsg := fmt.Sprintf("Error committing transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	out, err := cfg.conversationsToResponses(r.Context(), []database.Conversation{conversation}, userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error building conversation response: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	respondWithJSON(w, status, out[0])
+}
+
+func (cfg *apiConfig) handlerGetConversationMessages(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	conversationID, err := uuid.Parse(r.PathValue("conversationID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing page parameters: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	_, err = cfg.database.GetConversationForUser(r.Context(), database.GetConversationForUserParams{
+		ID:     conversationID,
+		UserID: userID,
+	})
+	if err != nil {
+		errMsg := "Error getting conversation by ID: Conversation not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	messages, err := cfg.database.GetDirectMessagesPage(r.Context(), database.GetDirectMessagesPageParams{
+		ConversationID:  conversationID,
+		CursorCreatedAt: page.cursorCreatedAt,
+		CursorID:        page.cursorID,
+		PageLimit:       page.limit + 1,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting messages: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	participants, err := cfg.database.GetConversationParticipants(r.Context(), []uuid.UUID{conversationID})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting participants: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	nextCursor := ""
+	if len(messages) > int(page.limit) {
+		messages = messages[:page.limit]
+		last := messages[len(messages)-1]
+		nextCursor = nextPageCursor(last.CreatedAt, last.ID)
+	}
+
+	out := make([]DirectMessageResponseBody, 0, len(messages))
+	for _, message := range messages {
+		out = append(out, databaseDirectMessageToResponse(message, participants))
+	}
+
+	respondWithJSON(w, http.StatusOK, DirectMessagesPageResponseBody{
+		Messages:   out,
+		NextCursor: nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerCreateConversationMessage(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	conversationID, err := uuid.Parse(r.PathValue("conversationID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error reading request body: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	params := DirectMessageRequestBody{}
+	err = json.Unmarshal(data, &params)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error unmarshalling data: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	body, err := validateDirectMessageBody(params.Body)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	_, err = cfg.database.GetConversationForUser(r.Context(), database.GetConversationForUserParams{
+		ID:     conversationID,
+		UserID: userID,
+	})
+	if err != nil {
+		errMsg := "Error getting conversation by ID: Conversation not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	participants, err := cfg.database.GetConversationParticipants(r.Context(), []uuid.UUID{conversationID})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting participants: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	participantIDs := make([]uuid.UUID, 0, len(participants))
+	for _, participant := range participants {
+		participantIDs = append(participantIDs, participant.UserID)
+	}
+
+	// Blocks made after the conversation started also silence it.
+	blocked, err := cfg.database.HasBlockAmong(r.Context(), participantIDs)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error checking blocks: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	if blocked {
+		errMsg := "Cannot send messages in a conversation between users who have blocked each other"
+		respondWithError(w, http.StatusForbidden, errMsg)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error starting transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	message, err := postDirectMessage(r.Context(), qtx, conversationID, userID, body)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		errMsg := fmt.Sprintf("Error committing transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, DirectMessageResponseBody{
+		ID:             message.ID,
+		CreatedAt:      message.CreatedAt,
+		ConversationID: message.ConversationID,
+		SenderID:       message.SenderID,
+		Body:           message.Body,
+		ReadBy:         []uuid.UUID{},
+	})
+}
+
+func (cfg *apiConfig) handlerMarkConversationRead(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	conversationID, err := uuid.Parse(r.PathValue("conversationID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	_, err = cfg.database.GetConversationForUser(r.Context(), database.GetConversationForUserParams{
+		ID:     conversationID,
+		UserID: userID,
+	})
+	if err != nil {
+		errMsg := "Error getting conversation by ID: Conversation not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	// The read receipt points at the newest message rather than the clock,
+	// so it stays comparable with message timestamps.
+	latest, err := cfg.database.GetLatestDirectMessages(r.Context(), []uuid.UUID{conversationID})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting latest message: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	if len(latest) > 0 {
+		err = cfg.database.MarkConversationRead(r.Context(), database.MarkConversationReadParams{
+			ReadAt:         latest[0].CreatedAt,
+			ConversationID: conversationID,
+			UserID:         userID,
+		})
+		if err != nil {
+			errMsg := fmt.Sprintf("Error marking conversation read: %v", err)
+			respondWithError(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// postDirectMessage stores a message, bumps the conversation to the top of
+// everyone's inbox and counts the message as read by its sender.
+func postDirectMessage(ctx context.Context, qtx *database.Queries, conversationID, senderID uuid.UUID, body string) (database.DirectMessage, error) {
+	message, err := qtx.CreateDirectMessage(ctx, database.CreateDirectMessageParams{
+		ConversationID: conversationID,
+		SenderID:       senderID,
+		Body:           body,
+	})
+	if err != nil {
+		return database.DirectMessage{}, fmt.Errorf("Error creating message: %w", err)
+	}
+
+	err = qtx.TouchConversation(ctx, database.TouchConversationParams{
+		ID:        conversationID,
+		UpdatedAt: message.CreatedAt,
+	})
+	if err != nil {
+		return database.DirectMessage{}, fmt.Errorf("Error updating conversation: %w", err)
+	}
+
+	err = qtx.MarkConversationRead(ctx, database.MarkConversationReadParams{
+		ReadAt:         message.CreatedAt,
+		ConversationID: conversationID,
+		UserID:         senderID,
+	})
+	if err != nil {
+		return database.DirectMessage{}, fmt.Errorf("Error marking conversation read: %w", err)
+	}
+
+	return message, nil
+}
+
+func (cfg *apiConfig) conversationsToResponses(ctx context.Context, conversations []database.Conversation, viewerID uuid.UUID) ([]ConversationResponseBody, error) {
+	ids := make([]uuid.UUID, 0, len(conversations))
+	for _, conversation := range conversations {
+		ids = append(ids, conversation.ID)
+	}
+
+	participants, err := cfg.database.GetConversationParticipants(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting participants: %w", err)
+	}
+	participantsByConversation := map[uuid.UUID][]database.ConversationParticipant{}
+	for _, participant := range participants {
+		participantsByConversation[participant.ConversationID] = append(participantsByConversation[participant.ConversationID], participant)
+	}
+
+	latest, err := cfg.database.GetLatestDirectMessages(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting latest messages: %w", err)
+	}
+	latestByConversation := make(map[uuid.UUID]database.DirectMessage, len(latest))
+	for _, message := range latest {
+		latestByConversation[message.ConversationID] = message
+	}
+
+	unreadCounts, err := cfg.database.GetConversationUnreadCounts(ctx, database.GetConversationUnreadCountsParams{
+		UserID:          viewerID,
+		ConversationIds: ids,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error getting unread counts: %w", err)
+	}
+	unreadByConversation := make(map[uuid.UUID]int64, len(unreadCounts))
+	for _, row := range unreadCounts {
+		unreadByConversation[row.ConversationID] = row.UnreadCount
+	}
+
+	out := make([]ConversationResponseBody, 0, len(conversations))
+	for _, conversation := range conversations {
+		conversationParticipants := participantsByConversation[conversation.ID]
+
+		participantResponses := make([]ConversationParticipantResponseBody, 0, len(conversationParticipants))
+		for _, participant := range conversationParticipants {
+			participantResponse := ConversationParticipantResponseBody{
+				UserID:   participant.UserID,
+				JoinedAt: participant.JoinedAt,
+			}
+			if participant.LastReadAt.Valid {
+				participantResponse.LastReadAt = &participant.LastReadAt.Time
+			}
+			participantResponses = append(participantResponses, participantResponse)
+		}
+
+		response := ConversationResponseBody{
+			ID:           conversation.ID,
+			CreatedAt:    conversation.CreatedAt,
+			UpdatedAt:    conversation.UpdatedAt,
+			Participants: participantResponses,
+			UnreadCount:  unreadByConversation[conversation.ID],
+		}
+		if message, ok := latestByConversation[conversation.ID]; ok {
+			lastMessage := databaseDirectMessageToResponse(message, conversationParticipants)
+			response.LastMessage = &lastMessage
+		}
+		out = append(out, response)
+	}
+
+	return out, nil
+}
+
+// databaseDirectMessageToResponse fills in read receipts: everyone other
+// than the sender whose last read position is at or past the message.
+func databaseDirectMessageToResponse(message database.DirectMessage, participants []database.ConversationParticipant) DirectMessageResponseBody {
+	readBy := []uuid.UUID{}
+	for _, participant := range participants {
+		if participant.UserID == message.SenderID || !participant.LastReadAt.Valid {
+			continue
+		}
+		if !participant.LastReadAt.Time.Before(message.CreatedAt) {
+			readBy = append(readBy, participant.UserID)
+		}
+	}
+
+	return DirectMessageResponseBody{
+		ID:             message.ID,
+		CreatedAt:      message.CreatedAt,
+		ConversationID: message.ConversationID,
+		SenderID:       message.SenderID,
+		Body:           message.Body,
+		ReadBy:         readBy,
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// blockStub answers HasBlockAmong for a set of blocker -> blocked pairs,
+// only counting blocks where both users were passed in.
+func blockStub(blocks map[uuid.UUID]uuid.UUID) fakeQuery {
+	return func(args []driver.Value) ([][]driver.Value, error) {
+		ids := map[uuid.UUID]bool{}
+		for _, id := range argUUIDs(args, 0) {
+			ids[id] = true
+		}
+		for blocker, blocked := range blocks {
+			if ids[blocker] && ids[blocked] {
+				return [][]driver.Value{{true}}, nil
+			}
+		}
+		return [][]driver.Value{{false}}, nil
+	}
+}
+
+func TestCreateConversation(t *testing.T) {
+	creator := uuid.New()
+	alice := uuid.New()
+	bob := uuid.New()
+	existing := database.Conversation{ID: uuid.New(), CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	type Case struct {
+		name            string
+		participantIDs  []uuid.UUID
+		body            string
+		blocks          map[uuid.UUID]uuid.UUID
+		directExists    bool
+		wantStatus      int
+		wantParticipant int
+	}
+
+	cases := []Case{
+		{
+			name:            "Group",
+			participantIDs:  []uuid.UUID{alice, bob},
+			wantStatus:      http.StatusCreated,
+			wantParticipant: 3,
+		},
+		{
+			name:           "Invitees blocked each other",
+			participantIDs: []uuid.UUID{alice, bob},
+			blocks:         map[uuid.UUID]uuid.UUID{alice: bob},
+			wantStatus:     http.StatusForbidden,
+		},
+		{
+			name:           "Invitee blocked the creator",
+			participantIDs: []uuid.UUID{alice},
+			blocks:         map[uuid.UUID]uuid.UUID{alice: creator},
+			wantStatus:     http.StatusForbidden,
+		},
+		{
+			name:           "Existing direct conversation",
+			participantIDs: []uuid.UUID{alice, creator, alice},
+			directExists:   true,
+			wantStatus:     http.StatusOK,
+		},
+		{
+			name:           "Only the creator",
+			participantIDs: []uuid.UUID{creator},
+			wantStatus:     http.StatusBadRequest,
+		},
+		{
+			name:           "Unknown participant",
+			participantIDs: []uuid.UUID{alice, uuid.New()},
+			wantStatus:     http.StatusNotFound,
+		},
+		{
+			name:           "Blank first message",
+			participantIDs: []uuid.UUID{alice},
+			body:           "   ",
+			wantStatus:     http.StatusBadRequest,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			fake.on("HasBlockAmong", blockStub(c.blocks))
+			fake.on("GetUsersByIds", func(args []driver.Value) ([][]driver.Value, error) {
+				rows := [][]driver.Value{}
+				for _, id := range argUUIDs(args, 0) {
+					if id == alice || id == bob {
+						rows = append(rows, modelRow(database.User{ID: id, AccountStatus: accountStatusActive}))
+					}
+				}
+				return rows, nil
+			})
+			fake.on("FindDirectConversation", func(args []driver.Value) ([][]driver.Value, error) {
+				if !c.directExists {
+					return nil, nil
+				}
+				return [][]driver.Value{modelRow(existing)}, nil
+			})
+			fake.onRows("CreateConversation", modelRow(database.Conversation{ID: uuid.New(), CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+			fake.on("AddConversationParticipant", execOK(1))
+			fake.on("GetConversationParticipants", noRows)
+			fake.on("GetLatestDirectMessages", noRows)
+			fake.on("GetConversationUnreadCounts", noRows)
+
+			data, _ := json.Marshal(ConversationRequestBody{ParticipantIDs: c.participantIDs, Body: c.body})
+			req := httptest.NewRequest(http.MethodPost, "/api/conversations", strings.NewReader(string(data)))
+			req.Header = authHeader(t, creator)
+			rec := httptest.NewRecorder()
+			cfg.handlerCreateConversation(rec, req)
+
+			if rec.Code != c.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, c.wantStatus, rec.Body)
+			}
+			if added := fake.called("AddConversationParticipant"); added != c.wantParticipant {
+				t.Errorf("Added %d participants, want %d", added, c.wantParticipant)
+			}
+		})
+	}
+}
+
+func TestCreateConversationMessage(t *testing.T) {
+	sender := uuid.New()
+	alice := uuid.New()
+	bob := uuid.New()
+	conversationID := uuid.New()
+
+	type Case struct {
+		name        string
+		body        string
+		member      bool
+		blocks      map[uuid.UUID]uuid.UUID
+		wantStatus  int
+		wantMessage bool
+	}
+
+	cases := []Case{
+		{
+			name:        "Message",
+			body:        "hello",
+			member:      true,
+			wantStatus:  http.StatusCreated,
+			wantMessage: true,
+		},
+		{
+			name:       "Other participants blocked each other",
+			body:       "hello",
+			member:     true,
+			blocks:     map[uuid.UUID]uuid.UUID{bob: alice},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Participant blocked the sender",
+			body:       "hello",
+			member:     true,
+			blocks:     map[uuid.UUID]uuid.UUID{alice: sender},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:        "Block with someone outside the conversation",
+			body:        "hello",
+			member:      true,
+			blocks:      map[uuid.UUID]uuid.UUID{alice: uuid.New()},
+			wantStatus:  http.StatusCreated,
+			wantMessage: true,
+		},
+		{
+			name:       "Not a participant",
+			body:       "hello",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Empty body",
+			body:       "",
+			member:     true,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			fake.on("HasBlockAmong", blockStub(c.blocks))
+			fake.on("GetConversationForUser", func(args []driver.Value) ([][]driver.Value, error) {
+				if !c.member {
+					return nil, nil
+				}
+				return [][]driver.Value{modelRow(database.Conversation{ID: conversationID})}, nil
+			})
+			participants := [][]driver.Value{}
+			for _, id := range []uuid.UUID{sender, alice, bob} {
+				participants = append(participants, modelRow(database.ConversationParticipant{ConversationID: conversationID, UserID: id}))
+			}
+			fake.onRows("GetConversationParticipants", participants...)
+			fake.onRows("CreateDirectMessage", modelRow(database.DirectMessage{
+				ID:             uuid.New(),
+				CreatedAt:      time.Now(),
+				ConversationID: conversationID,
+				SenderID:       sender,
+				Body:           c.body,
+			}))
+			fake.on("TouchConversation", execOK(1))
+			fake.on("MarkConversationRead", execOK(1))
+
+			data, _ := json.Marshal(DirectMessageRequestBody{Body: c.body})
+			req := httptest.NewRequest(http.MethodPost, "/api/conversations/"+conversationID.String()+"/messages", strings.NewReader(string(data)))
+			req.Header = authHeader(t, sender)
+			req.SetPathValue("conversationID", conversationID.String())
+			rec := httptest.NewRecorder()
+			cfg.handlerCreateConversationMessage(rec, req)
+
+			if rec.Code != c.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, c.wantStatus, rec.Body)
+			}
+			sent := fake.called("CreateDirectMessage") > 0
+			if sent != c.wantMessage {
+				t.Errorf("Sent = %v, want %v", sent, c.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/delroscol98/chirpy/internal/auth"
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerCreateDraft(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error reading request body: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	params := DraftRequestBody{}
+	err = json.Unmarshal(data, &params)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error unmarshalling data: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	err = validateDraftBody(params.Body)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	draft, err := cfg.database.CreateDraft(r.Context(), database.CreateDraftParams{
+		UserID:    userID,
+		Body:      params.Body,
+		InReplyTo: optionalUUID(params.InReplyTo),
+		QuoteOf:   optionalUUID(params.QuoteOf),
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error creating draft: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, databaseDraftToResponse(draft))
+}
+
+func (cfg *apiConfig) handlerGetDrafts(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing page parameters: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	// Most recently edited first, so the cursor carries updated_at.
+	drafts, err := cfg.database.GetDraftsPage(r.Context(), database.GetDraftsPageParams{
+		UserID:          userID,
+		CursorUpdatedAt: page.cursorCreatedAt,
+		CursorID:        page.cursorID,
+		PageLimit:       page.limit + 1,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting drafts: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	nextCursor := ""
+	if len(drafts) > int(page.limit) {
+		drafts = drafts[:page.limit]
+		last := drafts[len(drafts)-1]
+		nextCursor = nextPageCursor(last.UpdatedAt, last.ID)
+	}
+
+	out := make([]DraftResponseBody, 0, len(drafts))
+	for _, draft := range drafts {
+		out = append(out, databaseDraftToResponse(draft))
+	}
+
+	respondWithJSON(w, http.StatusOK, DraftsPageResponseBody{
+		Drafts:     out,
+		NextCursor: nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerGetDraftById(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	draftID, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	// Other users' drafts are reported as missing rather than forbidden.
+	draft, err := cfg.database.GetDraftForUser(r.Context(), database.GetDraftForUserParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if err != nil {
+		errMsg := "Draft not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseDraftToResponse(draft))
+}
+
+func (cfg *apiConfig) handlerUpdateDraft(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	draftID, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error reading request body: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	params := DraftRequestBody{}
+	err = json.Unmarshal(data, &params)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error unmarshalling data: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	err = validateDraftBody(params.Body)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	draft, err := cfg.database.UpdateDraft(r.Context(), database.UpdateDraftParams{
+		Body:      params.Body,
+		InReplyTo: optionalUUID(params.InReplyTo),
+		QuoteOf:   optionalUUID(params.QuoteOf),
+		ID:        draftID,
+		UserID:    userID,
+	})
+	if err != nil {
+		errMsg := "Draft not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseDraftToResponse(draft))
+}
+
+func (cfg *apiConfig) handlerDeleteDraft(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	draftID, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	deleted, err := cfg.database.DeleteDraft(r.Context(), database.DeleteDraftParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error deleting draft: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	if deleted == 0 {
+		errMsg := "Draft not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// handlerPublishDraft posts a draft as a chirp and deletes it in the same
+// transaction, so a draft is never published twice or lost halfway.
+func (cfg *apiConfig) handlerPublishDraft(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	draftID, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error starting transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+	notifications := notificationBatch{}
+
+	draft, err := qtx.GetDraftForUserForUpdate(r.Context(), database.GetDraftForUserForUpdateParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if err != nil {
+		errMsg := "Draft not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	params := ChirpRequestBody{Body: draft.Body}
+	if draft.InReplyTo.Valid {
+		params.InReplyTo = &draft.InReplyTo.UUID
+	}
+	if draft.QuoteOf.Valid {
+		params.QuoteOf = &draft.QuoteOf.UUID
+	}
+
+	pending, code, err := cfg.prepareChirp(r.Context(), qtx, userID, params)
+	if err != nil {
+		respondWithError(w, code, err.Error())
+		return
+	}
+
+	chirp, err := cfg.insertChirp(r.Context(), qtx, userID, pending, &notifications)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	_, err = qtx.DeleteDraft(r.Context(), database.DeleteDraftParams{
+		ID:     draft.ID,
+		UserID: userID,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error deleting draft: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		errMsg := fmt.Sprintf("Error committing transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	out, err := cfg.announceChirp(r.Context(), chirp, notifications)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, out)
+}
+
+func databaseDraftToResponse(draft database.ChirpDraft) DraftResponseBody {
+	out := DraftResponseBody{
+		ID:        draft.ID,
+		CreatedAt: draft.CreatedAt,
+		UpdatedAt: draft.UpdatedAt,
+		Body:      draft.Body,
+	}
+	if draft.InReplyTo.Valid {
+		out.InReplyTo = &draft.InReplyTo.UUID
+	}
+	if draft.QuoteOf.Valid {
+		out.QuoteOf = &draft.QuoteOf.UUID
+	}
+
+	return out
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestCreateDraft(t *testing.T) {
+	userID := uuid.New()
+
+	type Case struct {
+		name       string
+		body       string
+		wantStatus int
+	}
+
+	cases := []Case{
+		{
+			name:       "Longer than a chirp",
+			body:       strings.Repeat("a", maxChirpLength+1),
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "Empty",
+			body:       "",
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "Too long",
+			body:       strings.Repeat("a", maxDraftLength+1),
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			fake.on("CreateDraft", func(args []driver.Value) ([][]driver.Value, error) {
+				return [][]driver.Value{modelRow(database.ChirpDraft{ID: uuid.New(), UserID: argUUID(args, 0), Body: args[1].(string)})}, nil
+			})
+
+			data, _ := json.Marshal(DraftRequestBody{Body: c.body})
+			req := httptest.NewRequest(http.MethodPost, "/api/drafts", bytes.NewReader(data))
+			req.Header = authHeader(t, userID)
+			rec := httptest.NewRecorder()
+			cfg.handlerCreateDraft(rec, req)
+
+			if rec.Code != c.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, c.wantStatus, rec.Body)
+			}
+			if c.wantStatus != http.StatusCreated {
+				return
+			}
+			got := DraftResponseBody{}
+			json.Unmarshal(rec.Body.Bytes(), &got)
+			if got.Body != c.body {
+				t.Errorf("Body = %q, want %q", got.Body, c.body)
+			}
+		})
+	}
+}
+
+func TestPublishDraft(t *testing.T) {
+	ownerID := uuid.New()
+	parent := database.Chirp{ID: uuid.New(), Body: "parent", UserID: uuid.New()}
+
+	type Case struct {
+		name        string
+		userID      uuid.UUID
+		body        string
+		inReplyTo   uuid.UUID
+		wantStatus  int
+		wantDeleted bool
+	}
+
+	cases := []Case{
+		{
+			name:        "Publish",
+			userID:      ownerID,
+			body:        "ready",
+			wantStatus:  http.StatusCreated,
+			wantDeleted: true,
+		},
+		{
+			name:        "Publish a reply",
+			userID:      ownerID,
+			body:        "ready",
+			inReplyTo:   parent.ID,
+			wantStatus:  http.StatusCreated,
+			wantDeleted: true,
+		},
+		{
+			name:       "Another user's draft",
+			userID:     uuid.New(),
+			body:       "ready",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Too long to publish",
+			userID:     ownerID,
+			body:       strings.Repeat("a", maxChirpLength+1),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Reply target deleted",
+			userID:     ownerID,
+			body:       "ready",
+			inReplyTo:  uuid.New(),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			fake.stubChirpResponses()
+			fakeChirps(fake, parent)
+
+			draft := database.ChirpDraft{ID: uuid.New(), UserID: ownerID, Body: c.body}
+			if c.inReplyTo != uuid.Nil {
+				draft.InReplyTo = uuid.NullUUID{UUID: c.inReplyTo, Valid: true}
+			}
+			fake.on("GetDraftForUserForUpdate", func(args []driver.Value) ([][]driver.Value, error) {
+				if argUUID(args, 0) != draft.ID || argUUID(args, 1) != draft.UserID {
+					return nil, nil
+				}
+				return [][]driver.Value{modelRow(draft)}, nil
+			})
+			fake.on("CreateChirp", func(args []driver.Value) ([][]driver.Value, error) {
+				return [][]driver.Value{modelRow(database.Chirp{ID: uuid.New(), Body: args[0].(string), UserID: argUUID(args, 1), ParentID: draft.InReplyTo, RootID: draft.InReplyTo})}, nil
+			})
+			fake.onRows("CreateNotification", modelRow(database.Notification{ID: uuid.New(), UserID: parent.UserID, Type: notificationTypeReply}))
+			fake.on("DeleteDraft", execOK(1))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/drafts/"+draft.ID.String()+"/publish", nil)
+			req.Header = authHeader(t, c.userID)
+			req.SetPathValue("draftID", draft.ID.String())
+			rec := httptest.NewRecorder()
+			cfg.handlerPublishDraft(rec, req)
+
+			if rec.Code != c.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, c.wantStatus, rec.Body)
+			}
+			// The chirp and the draft's removal commit together or not at all.
+			deleted := fake.called("DeleteDraft") > 0 && fake.commits == 1
+			if deleted != c.wantDeleted {
+				t.Errorf("Draft deleted = %v, want %v", deleted, c.wantDeleted)
+			}
+			if c.wantStatus != http.StatusCreated {
+				return
+			}
+
+			got := ChirpResponseBody{}
+			json.Unmarshal(rec.Body.Bytes(), &got)
+			if got.Body != c.body || got.UserID != ownerID {
+				t.Errorf("Published %q by %v, want %q by %v", got.Body, got.UserID, c.body, ownerID)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/delroscol98/chirpy/internal/auth"
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerFollowUser(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	followeeID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	if followeeID == userID {
+		errMsg := "Users cannot follow themselves"
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	_, err = cfg.database.GetUserById(r.Context(), followeeID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting user by ID: %v", err)
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	blocked, err := cfg.isBlockedBetween(r.Context(), userID, followeeID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if blocked {
+		errMsg := "Cannot follow a blocked user"
+		respondWithError(w, http.StatusForbidden, errMsg)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error starting transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+	notifications := notificationBatch{}
+
+	inserted, err := qtx.FollowUser(r.Context(), database.FollowUserParams{
+		FollowerID: userID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error following user: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	if inserted > 0 {
+		err = cfg.notify(r.Context(), qtx, &notifications, followeeID, userID, notificationTypeFollow, uuid.NullUUID{})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		errMsg := fmt.Sprintf("Error committing transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	cfg.publishNotifications(notifications)
+	cfg.publishEvent(cfg.userStream, streamEventFollowsChanged, []string{followsTopic(userID)}, nil)
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func (cfg *apiConfig) handlerUnfollowUser(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	followeeID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	err = cfg.database.UnfollowUser(r.Context(), database.UnfollowUserParams{
+		FollowerID: userID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error unfollowing user: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	cfg.publishEvent(cfg.userStream, streamEventFollowsChanged, []string{followsTopic(userID)}, nil)
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func (cfg *apiConfig) handlerGetFollowers(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing page parameters: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	_, err = cfg.database.GetUserById(r.Context(), userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting user by ID: %v", err)
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	rows, err := cfg.database.GetFollowersPage(r.Context(), database.GetFollowersPageParams{
+		UserID:          userID,
+		CursorCreatedAt: page.cursorCreatedAt,
+		CursorID:        page.cursorID,
+		PageLimit:       page.limit + 1,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting followers: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	nextCursor := ""
+	if len(rows) > int(page.limit) {
+		rows = rows[:page.limit]
+		last := rows[len(rows)-1]
+		nextCursor = nextPageCursor(last.FollowedAt, last.User.ID)
+	}
+
+	out := make([]FollowResponseBody, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, FollowResponseBody{
+			PublicUserResponseBody: databaseUserToPublicResponse(row.User),
+			FollowedAt:             row.FollowedAt,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, FollowsPageResponseBody{
+		Users:      out,
+		NextCursor: nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerGetFollowing(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing page parameters: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	_, err = cfg.database.GetUserById(r.Context(), userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting user by ID: %v", err)
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	rows, err := cfg.database.GetFollowingPage(r.Context(), database.GetFollowingPageParams{
+		UserID:          userID,
+		CursorCreatedAt: page.cursorCreatedAt,
+		CursorID:        page.cursorID,
+		PageLimit:       page.limit + 1,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting followed users: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	nextCursor := ""
+	if len(rows) > int(page.limit) {
+		rows = rows[:page.limit]
+		last := rows[len(rows)-1]
+		nextCursor = nextPageCursor(last.FollowedAt, last.User.ID)
+	}
+
+	out := make([]FollowResponseBody, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, FollowResponseBody{
+			PublicUserResponseBody: databaseUserToPublicResponse(row.User),
+			FollowedAt:             row.FollowedAt,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, FollowsPageResponseBody{
+		Users:      out,
+		NextCursor: nextCursor,
+	})
+}
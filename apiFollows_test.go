@@ -0,0 +1,99 @@
+package main
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestFollowUser(t *testing.T) {
+	followerID := uuid.New()
+	followeeID := uuid.New()
+
+	type Case struct {
+		name             string
+		followeeID       uuid.UUID
+		alreadyFollowing bool
+		blocked          bool
+		wantStatus       int
+		wantNotification bool
+		wantEvent        bool
+	}
+
+	cases := []Case{
+		{
+			name:             "Follow",
+			followeeID:       followeeID,
+			wantStatus:       http.StatusNoContent,
+			wantNotification: true,
+			wantEvent:        true,
+		},
+		{
+			name:             "Already following",
+			followeeID:       followeeID,
+			alreadyFollowing: true,
+			wantStatus:       http.StatusNoContent,
+			wantEvent:        true,
+		},
+		{
+			name:       "Self",
+			followeeID: followerID,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Unknown user",
+			followeeID: uuid.New(),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Blocked",
+			followeeID: followeeID,
+			blocked:    true,
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			fake.onRows("HasBlockBetween", []driver.Value{c.blocked})
+			fake.on("GetUserById", func(args []driver.Value) ([][]driver.Value, error) {
+				if argUUID(args, 0) != followeeID {
+					return nil, nil
+				}
+				return [][]driver.Value{modelRow(database.User{ID: followeeID, AccountStatus: accountStatusActive})}, nil
+			})
+			inserted := 1
+			if c.alreadyFollowing {
+				inserted = 0
+			}
+			fake.on("FollowUser", execOK(inserted))
+			fake.onRows("CreateNotification", modelRow(database.Notification{ID: uuid.New(), UserID: followeeID, Type: notificationTypeFollow}))
+
+			sub, _, _ := cfg.userStream.Subscribe(0, followsTopic(followerID))
+			defer cfg.userStream.Unsubscribe(sub)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/users/"+c.followeeID.String()+"/follow", nil)
+			req.Header = authHeader(t, followerID)
+			req.SetPathValue("userID", c.followeeID.String())
+			rec := httptest.NewRecorder()
+			cfg.handlerFollowUser(rec, req)
+
+			if rec.Code != c.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, c.wantStatus, rec.Body)
+			}
+			notified := fake.called("CreateNotification") > 0
+			if notified != c.wantNotification {
+				t.Errorf("Notified = %v, want %v", notified, c.wantNotification)
+			}
+			gotEvent := len(sub.C) > 0
+			if gotEvent != c.wantEvent {
+				t.Errorf("Published follows_changed = %v, want %v", gotEvent, c.wantEvent)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/delroscol98/chirpy/internal/entities"
+	"github.com/delroscol98/chirpy/internal/pagination"
+)
+
+const maxTrendingWindow = 7 * 24 * time.Hour
+
+func (cfg *apiConfig) handlerGetHashtagChirps(w http.ResponseWriter, r *http.Request) {
+	tag := entities.NormalizeHashtag(r.PathValue("tag"))
+	if tag == "" {
+		errMsg := "Hashtag must not be empty"
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing page parameters: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	viewerID := cfg.viewerID(r)
+	chirps, err := cfg.database.GetHashtagChirpsPage(r.Context(), database.GetHashtagChirpsPageParams{
+		Tag:             tag,
+		ViewerID:        viewerID,
+		CursorCreatedAt: page.cursorCreatedAt,
+		CursorID:        page.cursorID,
+		PageLimit:       page.limit + 1,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting hashtag chirps: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	nextCursor := ""
+	if len(chirps) > int(page.limit) {
+		chirps = chirps[:page.limit]
+		last := chirps[len(chirps)-1]
+		nextCursor = nextPageCursor(last.CreatedAt, last.ID)
+	}
+
+	out, err := cfg.chirpsToResponses(r.Context(), chirps, viewerID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error building chirp responses: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ChirpsPageResponseBody{
+		Chirps:     out,
+		NextCursor: nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerGetTrendingHashtags(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	window := 24 * time.Hour
+	if rawWindow := query.Get("window"); rawWindow != "" {
+		parsed, err := time.ParseDuration(rawWindow)
+		if err != nil || parsed <= 0 || parsed > maxTrendingWindow {
+			errMsg := fmt.Sprintf("window must be a positive duration of at most %v", maxTrendingWindow)
+			respondWithError(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		window = parsed
+	}
+
+	limit, err := pagination.ParseLimit(query.Get("limit"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing limit: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	trending, err := cfg.database.GetTrendingHashtags(r.Context(), database.GetTrendingHashtagsParams{
+		Since:     time.Now().UTC().Add(-window),
+		PageLimit: limit,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting trending hashtags: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	out := make([]TrendingHashtagResponseBody, 0, len(trending))
+	for _, hashtag := range trending {
+		out = append(out, TrendingHashtagResponseBody{
+			Tag:        hashtag.Tag,
+			ChirpCount: hashtag.ChirpCount,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, TrendingHashtagsResponseBody{
+		Window:   window.String(),
+		Hashtags: out,
+	})
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestLikeChirp(t *testing.T) {
+	authorID := uuid.New()
+	likerID := uuid.New()
+	chirp := database.Chirp{ID: uuid.New(), Body: "likeable", UserID: authorID}
+	rechirp := database.Chirp{ID: uuid.New(), UserID: likerID, RechirpOf: uuid.NullUUID{UUID: chirp.ID, Valid: true}}
+	deleted := database.Chirp{ID: uuid.New(), UserID: authorID, DeletedAt: sql.NullTime{Time: time.Now(), Valid: true}}
+
+	type step struct {
+		unlike  bool
+		chirpID uuid.UUID
+		userID  uuid.UUID
+	}
+
+	type Case struct {
+		name              string
+		steps             []step
+		wantStatus        int
+		wantLikes         int
+		wantNotifications int
+	}
+
+	like := step{chirpID: chirp.ID, userID: likerID}
+	unlike := step{unlike: true, chirpID: chirp.ID, userID: likerID}
+
+	cases := []Case{
+		{
+			name:              "Like",
+			steps:             []step{like},
+			wantStatus:        http.StatusNoContent,
+			wantLikes:         1,
+			wantNotifications: 1,
+		},
+		{
+			name:              "Liking twice notifies once",
+			steps:             []step{like, like},
+			wantStatus:        http.StatusNoContent,
+			wantLikes:         1,
+			wantNotifications: 1,
+		},
+		{
+			name:              "Unliking twice",
+			steps:             []step{like, unlike, unlike},
+			wantStatus:        http.StatusNoContent,
+			wantLikes:         0,
+			wantNotifications: 1,
+		},
+		{
+			name:              "Own chirp",
+			steps:             []step{{chirpID: chirp.ID, userID: authorID}},
+			wantStatus:        http.StatusNoContent,
+			wantLikes:         1,
+			wantNotifications: 0,
+		},
+		{
+			name:              "Rechirp likes the original",
+			steps:             []step{{chirpID: rechirp.ID, userID: likerID}, like},
+			wantStatus:        http.StatusNoContent,
+			wantLikes:         1,
+			wantNotifications: 1,
+		},
+		{
+			name:       "Deleted chirp",
+			steps:      []step{{chirpID: deleted.ID, userID: likerID}},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			fake.on("GetChirpById", func(args []driver.Value) ([][]driver.Value, error) {
+				for _, stored := range []database.Chirp{chirp, rechirp, deleted} {
+					if stored.ID == argUUID(args, 0) {
+						return [][]driver.Value{modelRow(stored)}, nil
+					}
+				}
+				return nil, nil
+			})
+
+			// Mirrors the likes primary key: a repeated like inserts nothing.
+			likes := map[[2]uuid.UUID]bool{}
+			fake.on("LikeChirp", func(args []driver.Value) ([][]driver.Value, error) {
+				key := [2]uuid.UUID{argUUID(args, 0), argUUID(args, 1)}
+				if key[1] != chirp.ID {
+					t.Errorf("Liked %v, want %v", key[1], chirp.ID)
+				}
+				if likes[key] {
+					return nil, nil
+				}
+				likes[key] = true
+				return make([][]driver.Value, 1), nil
+			})
+			fake.on("UnlikeChirp", func(args []driver.Value) ([][]driver.Value, error) {
+				delete(likes, [2]uuid.UUID{argUUID(args, 0), argUUID(args, 1)})
+				return nil, nil
+			})
+			fake.onRows("CreateNotification", modelRow(database.Notification{ID: uuid.New(), UserID: authorID, Type: notificationTypeLike}))
+
+			var rec *httptest.ResponseRecorder
+			for _, s := range c.steps {
+				method, handler := http.MethodPost, cfg.handlerLikeChirp
+				if s.unlike {
+					method, handler = http.MethodDelete, cfg.handlerUnlikeChirp
+				}
+				req := httptest.NewRequest(method, "/api/chirps/"+s.chirpID.String()+"/likes", nil)
+				req.Header = authHeader(t, s.userID)
+				req.SetPathValue("chirpID", s.chirpID.String())
+				rec = httptest.NewRecorder()
+				handler(rec, req)
+			}
+
+			if rec.Code != c.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, c.wantStatus, rec.Body)
+			}
+			if len(likes) != c.wantLikes {
+				t.Errorf("Got %d likes, want %d", len(likes), c.wantLikes)
+			}
+			if notified := fake.called("CreateNotification"); notified != c.wantNotifications {
+				t.Errorf("Sent %d notifications, want %d", notified, c.wantNotifications)
+			}
+		})
+	}
+}
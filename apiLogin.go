@@ -44,6 +44,12 @@ func (cfg *apiConfig) handlerGetUserByEmail(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	err = accountRestriction(user.AccountStatus, user.StatusReason, user.StatusExpiresAt, time.Now().UTC())
+	if err != nil {
+		respondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
 	token, err := auth.MakeJWT(user.ID, cfg.secret, time.Hour)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error making JWT: %v", err)
@@ -73,6 +79,10 @@ func (cfg *apiConfig) handlerGetUserByEmail(w http.ResponseWriter, r *http.Reque
 		CreatedAt:    user.CreatedAt,
 		UpdatedAt:    user.UpdatedAt,
 		Email:        user.Email,
+		Handle:       user.Handle.String,
+		DisplayName:  user.DisplayName,
+		Bio:          user.Bio,
+		AvatarURL:    user.AvatarUrl,
 		Token:        token,
 		RefreshToken: refreshToken.Token,
 		IsChirpyRed:  user.IsChirpyRed,
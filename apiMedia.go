@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/delroscol98/chirpy/internal/auth"
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/delroscol98/chirpy/internal/media"
+	"github.com/google/uuid"
+)
+
+const (
+	mediaPurposeChirp  = "chirp"
+	mediaPurposeAvatar = "avatar"
+
+	maxUploadBytes = media.DefaultMaxBytes
+	// Leaves room for the multipart boundaries and headers around the file.
+	maxUploadRequestBytes = maxUploadBytes + 64<<10
+)
+
+func (cfg *apiConfig) handlerUploadMedia(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	upload, code, err := cfg.saveUpload(w, r, userID, mediaPurposeChirp)
+	if err != nil {
+		respondWithError(w, code, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, cfg.databaseMediaToResponse(upload))
+}
+
+func (cfg *apiConfig) handlerGetMediaById(w http.ResponseWriter, r *http.Request) {
+	mediaID, err := uuid.Parse(r.PathValue("mediaID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing media ID: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	upload, err := cfg.database.GetMediaUploadById(r.Context(), mediaID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting media: %v", err)
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, cfg.databaseMediaToResponse(upload))
+}
+
+func (cfg *apiConfig) handlerUploadAvatar(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	upload, code, err := cfg.saveUpload(w, r, userID, mediaPurposeAvatar)
+	if err != nil {
+		respondWithError(w, code, err.Error())
+		return
+	}
+
+	// Avatars are shown small everywhere, so point at the thumbnail. The
+	// upload it replaces is no longer referenced and is left to runMediaGC.
+	user, err := cfg.database.UpdateUserAvatarUrl(r.Context(), database.UpdateUserAvatarUrlParams{
+		AvatarUrl:      cfg.storage.URL(upload.ThumbnailKey),
+		AvatarUploadID: uuid.NullUUID{UUID: upload.ID, Valid: true},
+		ID:             userID,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error updating avatar: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, UserResponseBody{
+		ID:          user.ID,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+		Email:       user.Email,
+		Handle:      user.Handle.String,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarUrl,
+		IsChirpyRed: user.IsChirpyRed,
+	})
+}
+
+// saveUpload reads the multipart "file" field, cleans it up with the media
+// package, stores the image and its thumbnail, and records the upload. On
+// failure it returns the status code to respond with.
+func (cfg *apiConfig) saveUpload(w http.ResponseWriter, r *http.Request, userID uuid.UUID, purpose string) (database.MediaUpload, int, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadRequestBytes)
+	defer r.Body.Close()
+
+	err := r.ParseMultipartForm(maxUploadRequestBytes)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return database.MediaUpload{}, http.StatusRequestEntityTooLarge, fmt.Errorf("Uploads must be at most %d bytes", maxUploadBytes)
+	}
+	if err != nil {
+		return database.MediaUpload{}, http.StatusBadRequest, fmt.Errorf("Error parsing multipart form: %v", err)
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return database.MediaUpload{}, http.StatusBadRequest, fmt.Errorf("Error reading file field: %v", err)
+	}
+	defer file.Close()
+
+	processed, err := media.Process(file, maxUploadBytes)
+	if errors.Is(err, media.ErrTooLarge) {
+		return database.MediaUpload{}, http.StatusRequestEntityTooLarge, fmt.Errorf("Uploads must be at most %d bytes", maxUploadBytes)
+	}
+	if errors.Is(err, media.ErrUnsupportedType) {
+		return database.MediaUpload{}, http.StatusUnsupportedMediaType, err
+	}
+	if err != nil {
+		return database.MediaUpload{}, http.StatusBadRequest, err
+	}
+
+	mediaID := uuid.New()
+	key := fmt.Sprintf("media/%s/%s.%s", userID, mediaID, processed.Original.Extension)
+	thumbnailKey := fmt.Sprintf("media/%s/%s_thumb.%s", userID, mediaID, processed.Thumbnail.Extension)
+
+	err = cfg.storage.Put(r.Context(), key, bytes.NewReader(processed.Original.Data), processed.Original.ContentType)
+	if err != nil {
+		return database.MediaUpload{}, http.StatusInternalServerError, fmt.Errorf("Error storing image: %v", err)
+	}
+	err = cfg.storage.Put(r.Context(), thumbnailKey, bytes.NewReader(processed.Thumbnail.Data), processed.Thumbnail.ContentType)
+	if err != nil {
+		cfg.storage.Delete(r.Context(), key)
+		return database.MediaUpload{}, http.StatusInternalServerError, fmt.Errorf("Error storing thumbnail: %v", err)
+	}
+
+	upload, err := cfg.database.CreateMediaUpload(r.Context(), database.CreateMediaUploadParams{
+		ID:           mediaID,
+		UserID:       userID,
+		Purpose:      purpose,
+		ContentType:  processed.Original.ContentType,
+		StorageKey:   key,
+		ThumbnailKey: thumbnailKey,
+		Width:        int32(processed.Original.Width),
+		Height:       int32(processed.Original.Height),
+		SizeBytes:    int64(len(processed.Original.Data)),
+	})
+	if err != nil {
+		cfg.storage.Delete(r.Context(), key)
+		cfg.storage.Delete(r.Context(), thumbnailKey)
+		return database.MediaUpload{}, http.StatusInternalServerError, fmt.Errorf("Error saving upload: %v", err)
+	}
+
+	return upload, http.StatusCreated, nil
+}
+
+func (cfg *apiConfig) databaseMediaToResponse(upload database.MediaUpload) MediaResponseBody {
+	return MediaResponseBody{
+		ID:           upload.ID,
+		CreatedAt:    upload.CreatedAt,
+		UserID:       upload.UserID,
+		Purpose:      upload.Purpose,
+		ContentType:  upload.ContentType,
+		URL:          cfg.storage.URL(upload.StorageKey),
+		ThumbnailURL: cfg.storage.URL(upload.ThumbnailKey),
+		Width:        upload.Width,
+		Height:       upload.Height,
+		SizeBytes:    upload.SizeBytes,
+	}
+}
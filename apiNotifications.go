@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/delroscol98/chirpy/internal/auth"
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerGetNotifications(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing page parameters: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	notifications, err := cfg.database.GetNotificationsPage(r.Context(), database.GetNotificationsPageParams{
+		UserID:          userID,
+		UnreadOnly:      r.URL.Query().Get("unread") == "true",
+		CursorCreatedAt: page.cursorCreatedAt,
+		CursorID:        page.cursorID,
+		PageLimit:       page.limit + 1,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting notifications: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	unreadCount, err := cfg.database.CountUnreadNotifications(r.Context(), userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error counting unread notifications: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	nextCursor := ""
+	if len(notifications) > int(page.limit) {
+		notifications = notifications[:page.limit]
+		last := notifications[len(notifications)-1]
+		nextCursor = nextPageCursor(last.CreatedAt, last.ID)
+	}
+
+	out := make([]NotificationResponseBody, 0, len(notifications))
+	for _, notification := range notifications {
+		out = append(out, databaseNotificationToResponse(notification))
+	}
+
+	respondWithJSON(w, http.StatusOK, NotificationsPageResponseBody{
+		Notifications: out,
+		UnreadCount:   unreadCount,
+		NextCursor:    nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerMarkNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error reading request body: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	// An empty body or empty ids list marks everything as read.
+	req := NotificationsReadRequestBody{}
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &req)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error unmarshalling data: %v", err)
+			respondWithError(w, http.StatusBadRequest, errMsg)
+			return
+		}
+	}
+	if req.IDs == nil {
+		req.IDs = []uuid.UUID{}
+	}
+
+	markedRead, err := cfg.database.MarkNotificationsRead(r.Context(), database.MarkNotificationsReadParams{
+		UserID: userID,
+		Ids:    req.IDs,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error marking notifications read: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	unreadCount, err := cfg.database.CountUnreadNotifications(r.Context(), userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error counting unread notifications: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, NotificationsReadResponseBody{
+		MarkedRead:  markedRead,
+		UnreadCount: unreadCount,
+	})
+}
+
+func databaseNotificationToResponse(notification database.Notification) NotificationResponseBody {
+	out := NotificationResponseBody{
+		ID:        notification.ID,
+		CreatedAt: notification.CreatedAt,
+		Type:      notification.Type,
+	}
+	if notification.ActorID.Valid {
+		out.ActorID = &notification.ActorID.UUID
+	}
+	if notification.ChirpID.Valid {
+		out.ChirpID = &notification.ChirpID.UUID
+	}
+	if notification.ReadAt.Valid {
+		out.ReadAt = &notification.ReadAt.Time
+	}
+
+	return out
+}
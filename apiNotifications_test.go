@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestNotify(t *testing.T) {
+	userID := uuid.New()
+	actorID := uuid.New()
+
+	type Case struct {
+		name      string
+		actorID   uuid.UUID
+		wantSent  bool
+		wantActor bool
+	}
+
+	cases := []Case{
+		{name: "Another user", actorID: actorID, wantSent: true, wantActor: true},
+		{name: "System event", actorID: uuid.Nil, wantSent: true},
+		{name: "Own action", actorID: userID},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			fake.on("CreateNotification", func(args []driver.Value) ([][]driver.Value, error) {
+				if gotActor := args[1] != nil; gotActor != c.wantActor {
+					t.Errorf("Actor recorded = %v, want %v", gotActor, c.wantActor)
+				}
+				return [][]driver.Value{modelRow(database.Notification{ID: uuid.New(), UserID: argUUID(args, 0), Type: args[2].(string)})}, nil
+			})
+
+			sub, _, _ := cfg.userStream.Subscribe(0, notificationsTopic(userID))
+			defer cfg.userStream.Unsubscribe(sub)
+
+			batch := notificationBatch{}
+			err := cfg.notify(context.Background(), cfg.database, &batch, userID, c.actorID, notificationTypeLike, uuid.NullUUID{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Nothing is pushed until the batch is published after commit.
+			if len(sub.C) != 0 {
+				t.Errorf("Notification pushed before publishing")
+			}
+			cfg.publishNotifications(batch)
+
+			if sent := len(batch) > 0; sent != c.wantSent {
+				t.Errorf("Sent = %v, want %v", sent, c.wantSent)
+			}
+			if pushed := len(sub.C) > 0; pushed != c.wantSent {
+				t.Errorf("Pushed = %v, want %v", pushed, c.wantSent)
+			}
+		})
+	}
+}
+
+func TestMarkNotificationsRead(t *testing.T) {
+	userID := uuid.New()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type Case struct {
+		name           string
+		body           string
+		wantStatus     int
+		pick           []int
+		wantMarked     int64
+		wantUnread     int64
+		wantUnreadList int
+	}
+
+	cases := []Case{
+		{name: "Empty body marks everything", body: "", wantStatus: http.StatusOK, wantMarked: 3, wantUnread: 0},
+		{name: "Empty ids mark everything", body: `{"ids":[]}`, wantStatus: http.StatusOK, wantMarked: 3, wantUnread: 0},
+		{name: "Chosen ids", pick: []int{0, 2}, wantStatus: http.StatusOK, wantMarked: 2, wantUnread: 1, wantUnreadList: 1},
+		{name: "Already read", pick: []int{3}, wantStatus: http.StatusOK, wantMarked: 0, wantUnread: 3, wantUnreadList: 3},
+		{name: "Another user's notification", pick: []int{4}, wantStatus: http.StatusOK, wantMarked: 0, wantUnread: 3, wantUnreadList: 3},
+		{name: "Malformed body", body: `{"ids":"all"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+
+			// Three unread, one read and one belonging to someone else.
+			notifications := make([]database.Notification, 5)
+			for i := range notifications {
+				notifications[i] = database.Notification{ID: uuid.New(), CreatedAt: start.Add(time.Duration(i) * time.Minute), UserID: userID, Type: notificationTypeFollow}
+			}
+			notifications[3].ReadAt = sql.NullTime{Time: start, Valid: true}
+			notifications[4].UserID = uuid.New()
+
+			unread := func(id uuid.UUID) []*database.Notification {
+				list := []*database.Notification{}
+				for i := range notifications {
+					if notifications[i].UserID == id && !notifications[i].ReadAt.Valid {
+						list = append(list, &notifications[i])
+					}
+				}
+				return list
+			}
+			// Mirrors MarkNotificationsRead and the unread filters.
+			fake.on("MarkNotificationsRead", func(args []driver.Value) ([][]driver.Value, error) {
+				ids := argUUIDs(args, 1)
+				marked := [][]driver.Value{}
+				for _, notification := range unread(argUUID(args, 0)) {
+					if len(ids) == 0 || slices.Contains(ids, notification.ID) {
+						notification.ReadAt = sql.NullTime{Time: time.Now(), Valid: true}
+						marked = append(marked, nil)
+					}
+				}
+				return marked, nil
+			})
+			fake.on("CountUnreadNotifications", func(args []driver.Value) ([][]driver.Value, error) {
+				return [][]driver.Value{{int64(len(unread(argUUID(args, 0))))}}, nil
+			})
+			fake.on("GetNotificationsPage", func(args []driver.Value) ([][]driver.Value, error) {
+				if args[1] != true {
+					t.Errorf("GetNotificationsPage() unread_only = %v, want true", args[1])
+				}
+				rows := [][]driver.Value{}
+				for _, notification := range unread(argUUID(args, 0)) {
+					rows = append(rows, modelRow(*notification))
+				}
+				return rows, nil
+			})
+
+			body := c.body
+			if c.pick != nil {
+				ids := []uuid.UUID{}
+				for _, i := range c.pick {
+					ids = append(ids, notifications[i].ID)
+				}
+				data, _ := json.Marshal(NotificationsReadRequestBody{IDs: ids})
+				body = string(data)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/notifications/read", strings.NewReader(body))
+			req.Header = authHeader(t, userID)
+			rec := httptest.NewRecorder()
+			cfg.handlerMarkNotificationsRead(rec, req)
+
+			if rec.Code != c.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, c.wantStatus, rec.Body)
+			}
+			if c.wantStatus != http.StatusOK {
+				return
+			}
+			got := NotificationsReadResponseBody{}
+			json.Unmarshal(rec.Body.Bytes(), &got)
+			if got.MarkedRead != c.wantMarked || got.UnreadCount != c.wantUnread {
+				t.Errorf("Marked %d with %d unread, want %d with %d", got.MarkedRead, got.UnreadCount, c.wantMarked, c.wantUnread)
+			}
+
+			req = httptest.NewRequest(http.MethodGet, "/api/notifications?unread=true", nil)
+			req.Header = authHeader(t, userID)
+			rec = httptest.NewRecorder()
+			cfg.handlerGetNotifications(rec, req)
+			page := NotificationsPageResponseBody{}
+			json.Unmarshal(rec.Body.Bytes(), &page)
+			if len(page.Notifications) != c.wantUnreadList || page.UnreadCount != c.wantUnread {
+				t.Errorf("Listed %d unread with count %d, want %d with %d", len(page.Notifications), page.UnreadCount, c.wantUnreadList, c.wantUnread)
+			}
+		})
+	}
+}
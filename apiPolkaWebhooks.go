@@ -1,7 +1,9 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -52,18 +54,48 @@ func (cfg *apiConfig) handlerUpgradeUserChirpyRed(w http.ResponseWriter, r *http
 		return
 	}
 
-	user, err := cfg.database.UpgradeUserChirpyRed(r.Context(), userID)
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error starting transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+	notifications := notificationBatch{}
+
+	// Polka retries webhooks, so the upgrade only matches users who are not
+	// already Chirpy Red and concurrent retries announce it once.
+	user, err := qtx.UpgradeUserChirpyRed(r.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		_, err = qtx.GetUserById(r.Context(), userID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error getting user by ID: %v", err)
+			respondWithError(w, http.StatusNotFound, errMsg)
+			return
+		}
+		respondWithJSON(w, http.StatusNoContent, nil)
+		return
+	}
 	if err != nil {
 		errMsg := fmt.Sprintf("Error upgrading user to chirpy red: %v", err)
-		respondWithError(w, http.StatusNotFound, errMsg)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
 		return
 	}
 
-	if user.IsChirpyRed == false {
-		errMsg := "User not upgraded"
+	err = cfg.notify(r.Context(), qtx, &notifications, user.ID, uuid.Nil, notificationTypeChirpyRed, uuid.NullUUID{})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		errMsg := fmt.Sprintf("Error committing transaction: %v", err)
 		respondWithError(w, http.StatusInternalServerError, errMsg)
 		return
 	}
+	cfg.publishNotifications(notifications)
 
 	respondWithJSON(w, http.StatusNoContent, nil)
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestUpgradeUserChirpyRed(t *testing.T) {
+	userID := uuid.New()
+
+	type Case struct {
+		name             string
+		exists           bool
+		alreadyRed       bool
+		wantStatus       int
+		wantNotification bool
+	}
+
+	cases := []Case{
+		{
+			name:             "First upgrade",
+			exists:           true,
+			wantStatus:       http.StatusNoContent,
+			wantNotification: true,
+		},
+		{
+			name:       "Retried upgrade",
+			exists:     true,
+			alreadyRed: true,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Unknown user",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			cfg.polka_key = "polka-key"
+
+			user := database.User{ID: userID, AccountStatus: accountStatusActive, IsChirpyRed: c.alreadyRed}
+			fake.on("GetUserById", func(args []driver.Value) ([][]driver.Value, error) {
+				if !c.exists {
+					return nil, nil
+				}
+				return [][]driver.Value{modelRow(user)}, nil
+			})
+			// Mirrors the WHERE NOT is_chirpy_red condition.
+			fake.on("UpgradeUserChirpyRed", func(args []driver.Value) ([][]driver.Value, error) {
+				if !c.exists || c.alreadyRed {
+					return nil, nil
+				}
+				user.IsChirpyRed = true
+				return [][]driver.Value{modelRow(user)}, nil
+			})
+			fake.onRows("CreateNotification", modelRow(database.Notification{
+				ID:     uuid.New(),
+				UserID: userID,
+				Type:   notificationTypeChirpyRed,
+			}))
+
+			body := fmt.Sprintf(`{"event":"user.upgraded","data":{"user_id":%q}}`, userID)
+			req := httptest.NewRequest(http.MethodPost, "/api/polka/webhooks", strings.NewReader(body))
+			req.Header.Set("Authorization", "ApiKey polka-key")
+			rec := httptest.NewRecorder()
+			cfg.handlerUpgradeUserChirpyRed(rec, req)
+
+			if rec.Code != c.wantStatus {
+				t.Errorf("Status = %d, want %d: %s", rec.Code, c.wantStatus, rec.Body)
+			}
+			notified := fake.called("CreateNotification") > 0
+			if notified != c.wantNotification {
+				t.Errorf("Notified = %v, want %v", notified, c.wantNotification)
+			}
+		})
+	}
+}
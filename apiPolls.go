@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/auth"
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerVoteInPoll(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error reading request body: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	params := PollVoteRequestBody{}
+	err = json.Unmarshal(data, &params)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error unmarshalling data: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	chirp, err := cfg.getLiveChirp(r.Context(), chirpID)
+	if err != nil {
+		errMsg := "Chirp not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	blocked, err := cfg.isBlockedBetween(r.Context(), userID, chirp.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if blocked {
+		errMsg := "Cannot vote in a blocked user's poll"
+		respondWithError(w, http.StatusForbidden, errMsg)
+		return
+	}
+
+	poll, err := cfg.database.GetPollByChirpId(r.Context(), chirp.ID)
+	if err != nil {
+		errMsg := "Chirp does not have a poll"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	if !time.Now().Before(poll.ClosesAt) {
+		errMsg := "Poll has closed"
+		respondWithError(w, http.StatusConflict, errMsg)
+		return
+	}
+
+	// The table's keys do the rest: one vote per user per poll, and only
+	// for options that belong to this poll.
+	inserted, err := cfg.database.CastPollVote(r.Context(), database.CastPollVoteParams{
+		ChirpID:  chirp.ID,
+		UserID:   userID,
+		OptionID: params.OptionID,
+	})
+	if isForeignKeyViolation(err) {
+		errMsg := "Option does not belong to this poll"
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("Error casting vote: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	if inserted == 0 {
+		errMsg := "You have already voted in this poll"
+		respondWithError(w, http.StatusConflict, errMsg)
+		return
+	}
+
+	out, err := cfg.chirpToResponse(r.Context(), chirp, userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error building chirp response: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, out)
+}
+
+func (cfg *apiConfig) chirpPollsByChirp(ctx context.Context, chirpIDs []uuid.UUID, viewerID uuid.UUID) (map[uuid.UUID]*PollResponseBody, error) {
+	polls, err := cfg.database.GetPollsByChirpIds(ctx, chirpIDs)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting polls: %w", err)
+	}
+	pollByChirp := make(map[uuid.UUID]*PollResponseBody, len(polls))
+	if len(polls) == 0 {
+		return pollByChirp, nil
+	}
+
+	pollChirpIDs := make([]uuid.UUID, 0, len(polls))
+	for _, poll := range polls {
+		pollChirpIDs = append(pollChirpIDs, poll.ChirpID)
+	}
+
+	tallies, err := cfg.database.GetPollOptionTallies(ctx, pollChirpIDs)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting poll tallies: %w", err)
+	}
+
+	votedOption := map[uuid.UUID]uuid.UUID{}
+	if viewerID != uuid.Nil {
+		votes, err := cfg.database.GetUserPollVotes(ctx, database.GetUserPollVotesParams{
+			UserID:   viewerID,
+			ChirpIds: pollChirpIDs,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Error getting poll votes: %w", err)
+		}
+		for _, vote := range votes {
+			votedOption[vote.ChirpID] = vote.OptionID
+		}
+	}
+
+	now := time.Now()
+	for _, poll := range polls {
+		response := &PollResponseBody{
+			ClosesAt: poll.ClosesAt,
+			Closed:   !now.Before(poll.ClosesAt),
+			Options:  []PollOptionResponseBody{},
+		}
+		if optionID, ok := votedOption[poll.ChirpID]; ok {
+			response.VotedOptionID = &optionID
+		}
+		if response.Closed || response.VotedOptionID != nil {
+			response.TotalVotes = new(int64)
+		}
+		pollByChirp[poll.ChirpID] = response
+	}
+
+	for _, tally := range tallies {
+		response := pollByChirp[tally.ChirpID]
+		option := PollOptionResponseBody{ID: tally.ID, Label: tally.Label}
+		if response.TotalVotes != nil {
+			votes := tally.VoteCount
+			option.Votes = &votes
+			*response.TotalVotes += votes
+		}
+		response.Options = append(response.Options, option)
+	}
+
+	return pollByChirp, nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func TestVoteInPoll(t *testing.T) {
+	authorID := uuid.New()
+	voterID := uuid.New()
+	chirp := database.Chirp{ID: uuid.New(), Body: "which?", UserID: authorID}
+	plain := database.Chirp{ID: uuid.New(), Body: "no poll", UserID: authorID}
+	yes := uuid.New()
+	no := uuid.New()
+
+	type Case struct {
+		name       string
+		chirpID    uuid.UUID
+		optionID   uuid.UUID
+		times      int
+		closesIn   time.Duration
+		blocked    bool
+		wantStatus int
+		wantVotes  int64
+	}
+
+	cases := []Case{
+		{name: "Vote", chirpID: chirp.ID, optionID: yes, times: 1, closesIn: time.Hour, wantStatus: http.StatusCreated, wantVotes: 1},
+		{name: "Voting twice", chirpID: chirp.ID, optionID: yes, times: 2, closesIn: time.Hour, wantStatus: http.StatusConflict, wantVotes: 1},
+		{name: "Closed poll", chirpID: chirp.ID, optionID: yes, times: 1, closesIn: -time.Second, wantStatus: http.StatusConflict},
+		{name: "Option from another poll", chirpID: chirp.ID, optionID: uuid.New(), times: 1, closesIn: time.Hour, wantStatus: http.StatusBadRequest},
+		{name: "Blocked author", chirpID: chirp.ID, optionID: yes, times: 1, closesIn: time.Hour, blocked: true, wantStatus: http.StatusForbidden},
+		{name: "Chirp without a poll", chirpID: plain.ID, optionID: yes, times: 1, closesIn: time.Hour, wantStatus: http.StatusNotFound},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			fake.stubChirpResponses()
+			fake.onRows("HasBlockBetween", []driver.Value{c.blocked})
+			fakeChirps(fake, chirp, plain)
+
+			poll := database.Poll{ChirpID: chirp.ID, ClosesAt: time.Now().Add(c.closesIn)}
+			fake.on("GetPollByChirpId", func(args []driver.Value) ([][]driver.Value, error) {
+				if argUUID(args, 0) != poll.ChirpID {
+					return nil, nil
+				}
+				return [][]driver.Value{modelRow(poll)}, nil
+			})
+			fake.onRows("GetPollsByChirpIds", modelRow(poll))
+
+			// Mirrors poll_votes' keys: one vote per user per poll, and
+			// only for the poll's own options.
+			votes := map[uuid.UUID]uuid.UUID{}
+			fake.on("CastPollVote", func(args []driver.Value) ([][]driver.Value, error) {
+				optionID := argUUID(args, 2)
+				if optionID != yes && optionID != no {
+					return nil, &pq.Error{Code: "23503"}
+				}
+				if _, ok := votes[argUUID(args, 1)]; ok {
+					return nil, nil
+				}
+				votes[argUUID(args, 1)] = optionID
+				return make([][]driver.Value, 1), nil
+			})
+			fake.on("GetPollOptionTallies", func(args []driver.Value) ([][]driver.Value, error) {
+				rows := [][]driver.Value{}
+				for _, option := range []uuid.UUID{yes, no} {
+					count := int64(0)
+					for _, voted := range votes {
+						if voted == option {
+							count++
+						}
+					}
+					rows = append(rows, modelRow(database.GetPollOptionTalliesRow{ID: option, ChirpID: chirp.ID, Label: option.String(), VoteCount: count}))
+				}
+				return rows, nil
+			})
+			fake.on("GetUserPollVotes", func(args []driver.Value) ([][]driver.Value, error) {
+				optionID, ok := votes[argUUID(args, 0)]
+				if !ok {
+					return nil, nil
+				}
+				return [][]driver.Value{{chirp.ID.String(), optionID.String()}}, nil
+			})
+
+			var rec *httptest.ResponseRecorder
+			for i := 0; i < c.times; i++ {
+				data, _ := json.Marshal(PollVoteRequestBody{OptionID: c.optionID})
+				req := httptest.NewRequest(http.MethodPost, "/api/chirps/"+c.chirpID.String()+"/votes", bytes.NewReader(data))
+				req.Header = authHeader(t, voterID)
+				req.SetPathValue("chirpID", c.chirpID.String())
+				rec = httptest.NewRecorder()
+				cfg.handlerVoteInPoll(rec, req)
+			}
+
+			if rec.Code != c.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, c.wantStatus, rec.Body)
+			}
+			if int64(len(votes)) != c.wantVotes {
+				t.Errorf("Got %d votes, want %d", len(votes), c.wantVotes)
+			}
+			if c.wantStatus != http.StatusCreated {
+				return
+			}
+
+			got := ChirpResponseBody{}
+			json.Unmarshal(rec.Body.Bytes(), &got)
+			if got.Poll == nil || got.Poll.VotedOptionID == nil || *got.Poll.VotedOptionID != c.optionID {
+				t.Fatalf("Poll = %+v, want the vote for %v", got.Poll, c.optionID)
+			}
+			if got.Poll.TotalVotes == nil || *got.Poll.TotalVotes != c.wantVotes {
+				t.Errorf("Total votes = %v, want %d", got.Poll.TotalVotes, c.wantVotes)
+			}
+		})
+	}
+}
+
+func TestChirpPollsByChirp(t *testing.T) {
+	chirpID := uuid.New()
+	viewerID := uuid.New()
+	option := uuid.New()
+
+	type Case struct {
+		name        string
+		viewerID    uuid.UUID
+		closesIn    time.Duration
+		voted       bool
+		wantClosed  bool
+		wantResults bool
+	}
+
+	cases := []Case{
+		{name: "Open without a vote hides results", viewerID: viewerID, closesIn: time.Hour},
+		{name: "Anonymous viewer of an open poll", closesIn: time.Hour},
+		{name: "Open after voting shows results", viewerID: viewerID, closesIn: time.Hour, voted: true, wantResults: true},
+		{name: "Closed shows results", viewerID: viewerID, closesIn: -time.Hour, wantClosed: true, wantResults: true},
+		{name: "Closed to an anonymous viewer", closesIn: -time.Hour, wantClosed: true, wantResults: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			fake.onRows("GetPollsByChirpIds", modelRow(database.Poll{ChirpID: chirpID, ClosesAt: time.Now().Add(c.closesIn)}))
+			fake.onRows("GetPollOptionTallies", modelRow(database.GetPollOptionTalliesRow{ID: option, ChirpID: chirpID, Label: "yes", VoteCount: 3}))
+			fake.on("GetUserPollVotes", func(args []driver.Value) ([][]driver.Value, error) {
+				if !c.voted {
+					return nil, nil
+				}
+				return [][]driver.Value{{chirpID.String(), option.String()}}, nil
+			})
+
+			polls, err := cfg.chirpPollsByChirp(t.Context(), []uuid.UUID{chirpID}, c.viewerID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			poll := polls[chirpID]
+			if poll.Closed != c.wantClosed {
+				t.Errorf("Closed = %v, want %v", poll.Closed, c.wantClosed)
+			}
+			if results := poll.TotalVotes != nil && poll.Options[0].Votes != nil; results != c.wantResults {
+				t.Errorf("Results shown = %v, want %v", results, c.wantResults)
+			}
+			if c.viewerID == uuid.Nil && fake.called("GetUserPollVotes") != 0 {
+				t.Errorf("Looked up votes for an anonymous viewer")
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestRechirp(t *testing.T) {
+	authorID := uuid.New()
+	userID := uuid.New()
+	original := database.Chirp{ID: uuid.New(), Body: "original", UserID: authorID}
+
+	type Case struct {
+		name        string
+		times       int
+		blocked     bool
+		wantStatus  int
+		wantRechirp bool
+	}
+
+	cases := []Case{
+		{name: "Rechirp", times: 1, wantStatus: http.StatusCreated, wantRechirp: true},
+		{name: "Again returns the existing rechirp", times: 2, wantStatus: http.StatusOK, wantRechirp: true},
+		{name: "Blocked author", times: 1, blocked: true, wantStatus: http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			fake.stubChirpResponses()
+			fake.onRows("HasBlockBetween", []driver.Value{c.blocked})
+
+			// Mirrors the unique rechirp index: a second insert returns
+			// no row and the existing rechirp is looked up instead.
+			var rechirp *database.Chirp
+			fake.on("CreateRechirp", func(args []driver.Value) ([][]driver.Value, error) {
+				if rechirp != nil {
+					return nil, nil
+				}
+				rechirp = &database.Chirp{ID: uuid.New(), UserID: argUUID(args, 0), RechirpOf: uuid.NullUUID{UUID: argUUID(args, 1), Valid: true}}
+				return [][]driver.Value{modelRow(*rechirp)}, nil
+			})
+			fake.on("GetUserRechirp", func(args []driver.Value) ([][]driver.Value, error) {
+				return [][]driver.Value{modelRow(*rechirp)}, nil
+			})
+			fakeChirps(fake, original)
+
+			var rec *httptest.ResponseRecorder
+			for i := 0; i < c.times; i++ {
+				req := httptest.NewRequest(http.MethodPost, "/api/chirps/"+original.ID.String()+"/rechirp", nil)
+				req.Header = authHeader(t, userID)
+				req.SetPathValue("chirpID", original.ID.String())
+				rec = httptest.NewRecorder()
+				cfg.handlerRechirp(rec, req)
+			}
+
+			if rec.Code != c.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, c.wantStatus, rec.Body)
+			}
+			if !c.wantRechirp {
+				return
+			}
+
+			got := ChirpResponseBody{}
+			err := json.Unmarshal(rec.Body.Bytes(), &got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.ID != rechirp.ID || got.RechirpOf == nil || got.RechirpOf.ID != original.ID {
+				t.Errorf("Got %v rechirping %+v, want %v rechirping %v", got.ID, got.RechirpOf, rechirp.ID, original.ID)
+			}
+		})
+	}
+}
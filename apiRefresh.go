@@ -30,6 +30,12 @@ func (cfg *apiConfig) handlerGetRefreshToken(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	err = cfg.checkAccountActive(r.Context(), refreshToken.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
 	token, err := auth.MakeJWT(refreshToken.UserID, cfg.secret, time.Hour)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error making token: %v", err)
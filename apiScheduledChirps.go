@@ -0,0 +1,377 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/auth"
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	maxScheduleAhead            = 365 * 24 * time.Hour
+	scheduledChirpsPollInterval = 10 * time.Second
+)
+
+// scheduleChirp handles POST /api/chirps requests that carry publish_at.
+// The chirp is vetted now so mistakes surface immediately, and vetted again
+// by the publisher because replies, quotes and blocks can change meanwhile.
+func (cfg *apiConfig) scheduleChirp(w http.ResponseWriter, r *http.Request, userID uuid.UUID, params ChirpRequestBody) {
+	publishAt := params.PublishAt.UTC()
+	if !publishAt.After(time.Now()) {
+		errMsg := "publish_at must be in the future"
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+	if publishAt.After(time.Now().Add(maxScheduleAhead)) {
+		errMsg := fmt.Sprintf("Chirps can be scheduled at most %v ahead", maxScheduleAhead)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+	// Scheduled chirps do not store polls.
+	if params.Poll != nil {
+		errMsg := "Scheduled chirps cannot include polls"
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error starting transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	pending, code, err := cfg.prepareChirp(r.Context(), qtx, userID, params)
+	if err != nil {
+		respondWithError(w, code, err.Error())
+		return
+	}
+
+	scheduled, err := qtx.CreateScheduledChirp(r.Context(), database.CreateScheduledChirpParams{
+		UserID:    userID,
+		Body:      pending.Body,
+		InReplyTo: pending.ParentID,
+		QuoteOf:   pending.QuoteOf,
+		PublishAt: publishAt,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error scheduling chirp: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	for position, attachment := range pending.Media {
+		err = qtx.AddScheduledChirpMedia(r.Context(), database.AddScheduledChirpMediaParams{
+			ScheduledChirpID: scheduled.ID,
+			MediaID:          attachment.ID,
+			Position:         int32(position),
+			AltText:          attachment.AltText,
+		})
+		if err != nil {
+			errMsg := fmt.Sprintf("Error attaching media: %v", err)
+			respondWithError(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		errMsg := fmt.Sprintf("Error committing transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	out, err := cfg.scheduledChirpsToResponses(r.Context(), []database.ScheduledChirp{scheduled})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, out[0])
+}
+
+func (cfg *apiConfig) handlerGetScheduledChirps(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing page parameters: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	// Soonest first, so the cursor carries publish_at rather than
+	// created_at.
+	scheduled, err := cfg.database.GetScheduledChirpsPage(r.Context(), database.GetScheduledChirpsPageParams{
+		UserID:          userID,
+		CursorPublishAt: page.cursorCreatedAt,
+		CursorID:        page.cursorID,
+		PageLimit:       page.limit + 1,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting scheduled chirps: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	nextCursor := ""
+	if len(scheduled) > int(page.limit) {
+		scheduled = scheduled[:page.limit]
+		last := scheduled[len(scheduled)-1]
+		nextCursor = nextPageCursor(last.PublishAt, last.ID)
+	}
+
+	out, err := cfg.scheduledChirpsToResponses(r.Context(), scheduled)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ScheduledChirpsPageResponseBody{
+		ScheduledChirps: out,
+		NextCursor:      nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerCancelScheduledChirp(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	scheduledID, err := uuid.Parse(r.PathValue("scheduledID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	// A chirp the publisher is working on stays locked until it has been
+	// posted, so cancelling it then finds nothing to delete.
+	deleted, err := cfg.database.DeleteScheduledChirp(r.Context(), database.DeleteScheduledChirpParams{
+		ID:     scheduledID,
+		UserID: userID,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error cancelling scheduled chirp: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	if deleted == 0 {
+		errMsg := "Scheduled chirp not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func (cfg *apiConfig) scheduledChirpsToResponses(ctx context.Context, scheduled []database.ScheduledChirp) ([]ScheduledChirpResponseBody, error) {
+	out := make([]ScheduledChirpResponseBody, 0, len(scheduled))
+	if len(scheduled) == 0 {
+		return out, nil
+	}
+
+	scheduledIDs := make([]uuid.UUID, 0, len(scheduled))
+	for _, s := range scheduled {
+		scheduledIDs = append(scheduledIDs, s.ID)
+	}
+
+	rows, err := cfg.database.GetScheduledChirpMedia(ctx, scheduledIDs)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting scheduled chirp media: %w", err)
+	}
+	mediaByScheduled := make(map[uuid.UUID][]ChirpMediaResponseBody, len(scheduled))
+	for _, row := range rows {
+		mediaByScheduled[row.ScheduledChirpID] = append(mediaByScheduled[row.ScheduledChirpID], ChirpMediaResponseBody{
+			ID:           row.MediaID,
+			ContentType:  row.ContentType,
+			URL:          cfg.storage.URL(row.StorageKey),
+			ThumbnailURL: cfg.storage.URL(row.ThumbnailKey),
+			Width:        row.Width,
+			Height:       row.Height,
+			AltText:      row.AltText,
+		})
+	}
+
+	for _, s := range scheduled {
+		response := ScheduledChirpResponseBody{
+			ID:            s.ID,
+			CreatedAt:     s.CreatedAt,
+			UpdatedAt:     s.UpdatedAt,
+			Body:          s.Body,
+			UserID:        s.UserID,
+			Media:         mediaByScheduled[s.ID],
+			PublishAt:     s.PublishAt,
+			FailureReason: s.FailureReason,
+		}
+		if s.InReplyTo.Valid {
+			response.InReplyTo = &s.InReplyTo.UUID
+		}
+		if s.QuoteOf.Valid {
+			response.QuoteOf = &s.QuoteOf.UUID
+		}
+		if s.FailedAt.Valid {
+			response.FailedAt = &s.FailedAt.Time
+		}
+		if response.Media == nil {
+			response.Media = []ChirpMediaResponseBody{}
+		}
+		out = append(out, response)
+	}
+
+	return out, nil
+}
+
+func (cfg *apiConfig) runScheduledChirpPublisher() {
+	ticker := time.NewTicker(scheduledChirpsPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		published, err := cfg.publishDueChirps(context.Background(), time.Now().UTC())
+		if err != nil {
+			log.Printf("Error publishing scheduled chirps: %v", err)
+		} else if published > 0 {
+			log.Printf("Processed %d scheduled chirps", published)
+		}
+	}
+}
+
+// publishDueChirps works through every scheduled chirp due by now. Each is
+// claimed with FOR UPDATE SKIP LOCKED in its own transaction, so several
+// replicas can run the publisher without posting anything twice.
+func (cfg *apiConfig) publishDueChirps(ctx context.Context, now time.Time) (int, error) {
+	total := 0
+	for {
+		processed, err := cfg.publishScheduledChirp(ctx, now)
+		if err != nil || !processed {
+			return total, err
+		}
+		total++
+	}
+}
+
+// publishScheduledChirp reports whether it found a due chirp. One whose
+// author is suspended or banned, or that no longer passes prepareChirp, is
+// marked failed rather than retried forever.
+func (cfg *apiConfig) publishScheduledChirp(ctx context.Context, now time.Time) (bool, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("Error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	scheduled, err := qtx.ClaimDueScheduledChirp(ctx, now)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("Error claiming scheduled chirp: %w", err)
+	}
+
+	status, err := qtx.GetUserAccountStatus(ctx, scheduled.UserID)
+	if err != nil {
+		return false, fmt.Errorf("Error getting user by ID: %w", err)
+	}
+	err = accountRestriction(status.AccountStatus, status.StatusReason, status.StatusExpiresAt, now)
+	if err != nil {
+		tx.Rollback()
+		return true, cfg.failScheduledChirp(ctx, scheduled.ID, err)
+	}
+
+	rows, err := qtx.GetScheduledChirpMedia(ctx, []uuid.UUID{scheduled.ID})
+	if err != nil {
+		return false, fmt.Errorf("Error getting scheduled chirp media: %w", err)
+	}
+	params := ChirpRequestBody{Body: scheduled.Body}
+	for _, row := range rows {
+		params.Media = append(params.Media, ChirpMediaRequestBody{ID: row.MediaID, AltText: row.AltText})
+	}
+	if scheduled.InReplyTo.Valid {
+		params.InReplyTo = &scheduled.InReplyTo.UUID
+	}
+	if scheduled.QuoteOf.Valid {
+		params.QuoteOf = &scheduled.QuoteOf.UUID
+	}
+
+	// Deleting first releases the uploads so prepareChirp sees them as free.
+	_, err = qtx.DeleteScheduledChirp(ctx, database.DeleteScheduledChirpParams{
+		ID:     scheduled.ID,
+		UserID: scheduled.UserID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("Error removing scheduled chirp: %w", err)
+	}
+
+	pending, code, err := cfg.prepareChirp(ctx, qtx, scheduled.UserID, params)
+	if err != nil {
+		if code >= http.StatusInternalServerError {
+			return false, err
+		}
+		tx.Rollback()
+		return true, cfg.failScheduledChirp(ctx, scheduled.ID, err)
+	}
+
+	notifications := notificationBatch{}
+	chirp, err := cfg.insertChirp(ctx, qtx, scheduled.UserID, pending, &notifications)
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, fmt.Errorf("Error committing transaction: %w", err)
+	}
+
+	_, err = cfg.announceChirp(ctx, chirp, notifications)
+	if err != nil {
+		log.Printf("Error announcing scheduled chirp %s: %v", chirp.ID, err)
+	}
+
+	return true, nil
+}
+
+// failScheduledChirp records why a chirp could not be published and
+// releases its uploads so they can be attached again or collected.
+func (cfg *apiConfig) failScheduledChirp(ctx context.Context, id uuid.UUID, reason error) error {
+	err := cfg.database.MarkScheduledChirpFailed(ctx, database.MarkScheduledChirpFailedParams{
+		ID:            id,
+		FailureReason: reason.Error(),
+	})
+	if err != nil {
+		return fmt.Errorf("Error marking scheduled chirp failed: %w", err)
+	}
+
+	return nil
+}
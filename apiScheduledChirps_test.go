@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestPublishDueChirps(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	authorID := uuid.New()
+	parent := database.Chirp{ID: uuid.New(), Body: "parent", UserID: uuid.New()}
+
+	type Case struct {
+		name          string
+		due           bool
+		inReplyTo     uuid.UUID
+		status        string
+		blocked       bool
+		markFails     bool
+		wantProcessed int
+		wantPublished bool
+		wantFailure   string
+		wantErr       bool
+	}
+
+	cases := []Case{
+		{
+			name: "Nothing due",
+		},
+		{
+			name:          "Published",
+			due:           true,
+			wantProcessed: 1,
+			wantPublished: true,
+		},
+		{
+			name:          "Reply is published",
+			due:           true,
+			inReplyTo:     parent.ID,
+			wantProcessed: 1,
+			wantPublished: true,
+		},
+		{
+			name:          "Suspended author",
+			due:           true,
+			status:        accountStatusSuspended,
+			wantProcessed: 1,
+			wantFailure:   "Account is suspended",
+		},
+		{
+			name:          "Reply target deleted",
+			due:           true,
+			inReplyTo:     uuid.New(),
+			wantProcessed: 1,
+			wantFailure:   "Chirp being replied to does not exist",
+		},
+		{
+			name:          "Reply target blocked the author",
+			due:           true,
+			inReplyTo:     parent.ID,
+			blocked:       true,
+			wantProcessed: 1,
+			wantFailure:   "Cannot reply to a blocked user",
+		},
+		{
+			name:        "Marking failed errors",
+			due:         true,
+			status:      accountStatusBanned,
+			markFails:   true,
+			wantFailure: "Account is banned",
+			wantErr:     true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			fake.stubChirpResponses()
+			fake.onRows("HasBlockBetween", []driver.Value{c.blocked})
+			fakeChirps(fake, parent)
+			if c.status != "" {
+				fake.onRows("GetUserAccountStatus", []driver.Value{c.status, "", nil})
+			}
+
+			scheduled := database.ScheduledChirp{ID: uuid.New(), UserID: authorID, Body: "later", PublishAt: now.Add(-time.Minute)}
+			if c.inReplyTo != uuid.Nil {
+				scheduled.InReplyTo = uuid.NullUUID{UUID: c.inReplyTo, Valid: true}
+			}
+			// Mirrors ClaimDueScheduledChirp: once published or failed the
+			// chirp is no longer due.
+			due := c.due
+			fake.on("ClaimDueScheduledChirp", func(args []driver.Value) ([][]driver.Value, error) {
+				if !due {
+					return nil, nil
+				}
+				due = false
+				return [][]driver.Value{modelRow(scheduled)}, nil
+			})
+			fake.on("GetScheduledChirpMedia", noRows)
+			fake.on("DeleteScheduledChirp", execOK(1))
+			fake.on("CreateChirp", func(args []driver.Value) ([][]driver.Value, error) {
+				return [][]driver.Value{modelRow(database.Chirp{ID: uuid.New(), Body: args[0].(string), UserID: authorID, ParentID: scheduled.InReplyTo, RootID: scheduled.InReplyTo})}, nil
+			})
+			fake.onRows("CreateNotification", modelRow(database.Notification{ID: uuid.New(), UserID: parent.UserID, Type: notificationTypeReply}))
+
+			failure := ""
+			fake.on("MarkScheduledChirpFailed", func(args []driver.Value) ([][]driver.Value, error) {
+				if argUUID(args, 0) != scheduled.ID {
+					t.Errorf("Marked %v failed, want %v", args[0], scheduled.ID)
+				}
+				failure = args[1].(string)
+				if c.markFails {
+					return nil, errors.New("connection reset")
+				}
+				return nil, nil
+			})
+
+			processed, err := cfg.publishDueChirps(context.Background(), now)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("publishDueChirps() error = %v, want error %v", err, c.wantErr)
+			}
+			if processed != c.wantProcessed {
+				t.Errorf("publishDueChirps() = %d, want %d", processed, c.wantProcessed)
+			}
+			if published := fake.called("CreateChirp") > 0; published != c.wantPublished {
+				t.Errorf("Published = %v, want %v", published, c.wantPublished)
+			}
+			if failure != c.wantFailure {
+				t.Errorf("Failure reason = %q, want %q", failure, c.wantFailure)
+			}
+			if c.wantFailure != "" && fake.commits != 0 {
+				t.Errorf("Committed the claim of a chirp that failed")
+			}
+		})
+	}
+}
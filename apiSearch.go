@@ -0,0 +1,123 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/delroscol98/chirpy/internal/pagination"
+	"github.com/delroscol98/chirpy/internal/search"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerSearchChirps(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	tsQuery, err := search.BuildTSQuery(query.Get("q"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing search query: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	limit, err := pagination.ParseLimit(query.Get("limit"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing limit: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	offset := int32(0)
+	if rawCursor := query.Get("cursor"); rawCursor != "" {
+		offset, err = pagination.DecodeOffsetCursor(rawCursor)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error parsing cursor: %v", err)
+			respondWithError(w, http.StatusBadRequest, errMsg)
+			return
+		}
+	}
+
+	authorID := uuid.NullUUID{}
+	if rawAuthorID := query.Get("author_id"); rawAuthorID != "" {
+		id, err := uuid.Parse(rawAuthorID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error parsing author_id: %v", err)
+			respondWithError(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		authorID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	since, err := parseSearchTime(query.Get("since"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing since: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	until, err := parseSearchTime(query.Get("until"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing until: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	viewerID := cfg.viewerID(r)
+	rows, err := cfg.database.SearchChirps(r.Context(), database.SearchChirpsParams{
+		Query:      tsQuery,
+		AuthorID:   authorID,
+		ViewerID:   viewerID,
+		Since:      since,
+		Until:      until,
+		PageLimit:  limit + 1,
+		PageOffset: offset,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error searching chirps: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	// Results stop at the deepest offset a cursor may carry.
+	nextCursor := ""
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+		if offset+limit <= pagination.MaxOffset {
+			nextCursor = pagination.EncodeOffsetCursor(offset + limit)
+		}
+	}
+
+	chirps := make([]database.Chirp, 0, len(rows))
+	for _, row := range rows {
+		chirps = append(chirps, row.Chirp)
+	}
+
+	out, err := cfg.chirpsToResponses(r.Context(), chirps, viewerID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error building chirp responses: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ChirpsPageResponseBody{
+		Chirps:     out,
+		NextCursor: nextCursor,
+	})
+}
+
+func parseSearchTime(raw string) (sql.NullTime, error) {
+	if raw == "" {
+		return sql.NullTime{}, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		parsed, err := time.Parse(layout, raw)
+		if err == nil {
+			return sql.NullTime{Time: parsed.UTC(), Valid: true}, nil
+		}
+	}
+
+	return sql.NullTime{}, fmt.Errorf("%q is not an RFC 3339 time or YYYY-MM-DD date", raw)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/delroscol98/chirpy/internal/entities"
+	"github.com/delroscol98/chirpy/internal/stream"
+	"github.com/google/uuid"
+)
+
+const (
+	streamEventChirpCreated   = "chirp_created"
+	streamEventChirpDeleted   = "chirp_deleted"
+	streamEventChirpRestored  = "chirp_restored"
+	streamEventNotification   = "notification"
+	streamEventFollowsChanged = "follows_changed"
+	streamEventReset          = "reset"
+
+	streamEventAccountRestricted = "account_restricted"
+
+	streamHeartbeatInterval = 15 * time.Second
+)
+
+func (cfg *apiConfig) handlerStreamChirps(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		errMsg := "Streaming unsupported"
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	authorTopic := ""
+	if rawAuthorID := r.URL.Query().Get("author_id"); rawAuthorID != "" {
+		id, err := uuid.Parse(rawAuthorID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+			respondWithError(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		authorTopic = userTopic(id)
+	}
+
+	// EventSource sends Last-Event-ID on reconnect. The query parameter lets
+	// clients that cannot set headers resume too.
+	rawLastEventID := r.Header.Get("Last-Event-ID")
+	if rawLastEventID == "" {
+		rawLastEventID = r.URL.Query().Get("last_event_id")
+	}
+	lastEventID := uint64(0)
+	if rawLastEventID != "" {
+		id, err := strconv.ParseUint(rawLastEventID, 10, 64)
+		if err != nil {
+			errMsg := fmt.Sprintf("Error parsing last event ID: %v", err)
+			respondWithError(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		lastEventID = id
+	}
+
+	// Signed-in viewers never see chirps from users they have blocked,
+	// been blocked by or muted. The set is reloaded whenever it changes.
+	viewerID := cfg.viewerID(r)
+	hidden := map[uuid.UUID]bool{}
+	var userEvents <-chan stream.Event
+	if viewerID != uuid.Nil {
+		var err error
+		hidden, err = cfg.loadHiddenAuthors(r.Context(), viewerID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		userSub, _, _ := cfg.userStream.Subscribe(0, followsTopic(viewerID), accountTopic(viewerID))
+		defer cfg.userStream.Unsubscribe(userSub)
+		userEvents = userSub.C
+	}
+
+	topics := []string{}
+	if authorTopic != "" {
+		topics = append(topics, authorTopic)
+	}
+	sub, replay, complete := cfg.chirpStream.Subscribe(lastEventID, topics...)
+	defer cfg.chirpStream.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Events the client missed are gone, so it is told to reload and then
+	// resume from the latest event instead of silently skipping them.
+	if !complete {
+		replay = nil
+		reset := stream.Event{ID: cfg.chirpStream.LastID(), Type: streamEventReset, Data: []byte("{}")}
+		if stream.WriteEvent(w, reset) != nil {
+			return
+		}
+	}
+	for _, event := range replay {
+		if hidesChirpEvent(event, hidden) {
+			continue
+		}
+		if stream.WriteEvent(w, event) != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if stream.WriteComment(w, "heartbeat") != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if hidesChirpEvent(event, hidden) {
+				continue
+			}
+			if stream.WriteEvent(w, event) != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-userEvents:
+			if !ok {
+				return
+			}
+			if event.Type == streamEventAccountRestricted {
+				return
+			}
+			if event.Type != streamEventFollowsChanged {
+				continue
+			}
+			reloaded, err := cfg.loadHiddenAuthors(r.Context(), viewerID)
+			if err != nil {
+				log.Printf("Error reloading hidden authors: %v", err)
+				continue
+			}
+			hidden = reloaded
+		}
+	}
+}
+
+func userTopic(userID uuid.UUID) string {
+	return "user:" + userID.String()
+}
+
+func hashtagTopic(tag string) string {
+	return "hashtag:" + tag
+}
+
+// quoteTopic tags chirps that quote userID, so viewers who block or mute
+// that user can drop them too.
+func quoteTopic(userID uuid.UUID) string {
+	return "quote:" + userID.String()
+}
+
+func notificationsTopic(userID uuid.UUID) string {
+	return "notifications:" + userID.String()
+}
+
+func followsTopic(userID uuid.UUID) string {
+	return "follows:" + userID.String()
+}
+
+// accountTopic carries changes to userID's account status, so open
+// connections can be closed when the account is suspended or banned.
+func accountTopic(userID uuid.UUID) string {
+	return "account:" + userID.String()
+}
+
+// publishChirpEvent broadcasts a committed chirp change, tagged with its
+// author, hashtags and any quoted author so subscribers can filter on them.
+func (cfg *apiConfig) publishChirpEvent(eventType string, chirp database.Chirp, payload any) {
+	topics := []string{userTopic(chirp.UserID)}
+	if out, ok := payload.(ChirpResponseBody); ok && out.QuoteOf != nil {
+		topics = append(topics, quoteTopic(out.QuoteOf.UserID))
+	}
+	for _, tag := range entities.ExtractHashtags(chirp.Body) {
+		topics = append(topics, hashtagTopic(tag))
+	}
+
+	cfg.publishEvent(cfg.chirpStream, eventType, topics, payload)
+}
+
+// publishEvent is only called once the change it describes has committed.
+// Failures only affect live listeners, so they are logged, not returned.
+func (cfg *apiConfig) publishEvent(broadcaster stream.Broadcaster, eventType string, topics []string, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshalling %s event: %v", eventType, err)
+		return
+	}
+
+	broadcaster.Publish(eventType, topics, data)
+}
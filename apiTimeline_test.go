@@ -0,0 +1,116 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestGetTimeline(t *testing.T) {
+	viewerID := uuid.New()
+	followed := uuid.New()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Pairs of chirps share a timestamp so pages must break ties by ID.
+	chirps := make([]database.Chirp, 7)
+	for i := range chirps {
+		createdAt := start.Add(time.Duration(i/2) * time.Minute)
+		chirps[i] = database.Chirp{ID: uuid.New(), CreatedAt: createdAt, UpdatedAt: createdAt, Body: "chirp", UserID: followed}
+	}
+	slices.SortFunc(chirps, func(a, b database.Chirp) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(b.ID.String(), a.ID.String())
+	})
+
+	type Case struct {
+		name  string
+		limit string
+	}
+
+	cases := []Case{
+		{name: "One per page", limit: "1"},
+		{name: "Pages of two", limit: "2"},
+		{name: "Single page", limit: "20"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			fake.stubChirpResponses()
+			// Mirrors GetTimelinePage: newest first, after the cursor.
+			fake.on("GetTimelinePage", func(args []driver.Value) ([][]driver.Value, error) {
+				if argUUID(args, 0) != viewerID {
+					t.Errorf("GetTimelinePage() for %v, want %v", args[0], viewerID)
+				}
+				cursorAt, hasCursor := args[1].(time.Time)
+				cursorID := ""
+				if hasCursor {
+					cursorID = argUUID(args, 2).String()
+				}
+				limit := int(args[3].(int64))
+
+				rows := [][]driver.Value{}
+				for _, chirp := range chirps {
+					if hasCursor {
+						c := chirp.CreatedAt.Compare(cursorAt)
+						if c > 0 || (c == 0 && chirp.ID.String() >= cursorID) {
+							continue
+						}
+					}
+					if len(rows) < limit {
+						rows = append(rows, modelRow(chirp))
+					}
+				}
+				return rows, nil
+			})
+
+			seen := []uuid.UUID{}
+			cursor := ""
+			for page := 0; page == 0 || cursor != ""; page++ {
+				if page > len(chirps) {
+					t.Fatal("Pagination did not terminate")
+				}
+
+				target := "/api/timeline?limit=" + c.limit
+				if cursor != "" {
+					target += "&cursor=" + cursor
+				}
+				req := httptest.NewRequest(http.MethodGet, target, nil)
+				req.Header = authHeader(t, viewerID)
+				rec := httptest.NewRecorder()
+				cfg.handlerGetTimeline(rec, req)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("Status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+				}
+
+				out := ChirpsPageResponseBody{}
+				err := json.Unmarshal(rec.Body.Bytes(), &out)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, chirp := range out.Chirps {
+					seen = append(seen, chirp.ID)
+				}
+				cursor = out.NextCursor
+			}
+
+			want := []uuid.UUID{}
+			for _, chirp := range chirps {
+				want = append(want, chirp.ID)
+			}
+			if !slices.Equal(seen, want) {
+				t.Errorf("Timeline = %v, want %v", seen, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/auth"
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	trashPurgeInterval  = time.Hour
+	trashPurgeBatchSize = 100
+)
+
+func (cfg *apiConfig) handlerGetTrash(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	page, err := parsePageParams(r)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing page parameters: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	// Most recently deleted first, so the cursor carries deleted_at.
+	chirps, err := cfg.database.GetTrashPage(r.Context(), database.GetTrashPageParams{
+		UserID:          userID,
+		DeletedAfter:    time.Now().UTC().Add(-cfg.trashRetention),
+		CursorDeletedAt: page.cursorCreatedAt,
+		CursorID:        page.cursorID,
+		PageLimit:       page.limit + 1,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting trash: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	nextCursor := ""
+	if len(chirps) > int(page.limit) {
+		chirps = chirps[:page.limit]
+		last := chirps[len(chirps)-1]
+		nextCursor = nextPageCursor(last.DeletedAt.Time, last.ID)
+	}
+
+	responses, err := cfg.chirpsToResponses(r.Context(), chirps, userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error building chirp responses: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	out := make([]TrashedChirpResponseBody, 0, len(responses))
+	for i, response := range responses {
+		deletedAt := chirps[i].DeletedAt.Time
+		out = append(out, TrashedChirpResponseBody{
+			ChirpResponseBody: response,
+			DeletedAt:         deletedAt,
+			RestorableUntil:   deletedAt.Add(cfg.trashRetention),
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, TrashPageResponseBody{
+		Chirps:     out,
+		NextCursor: nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerRestoreChirp(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing string uuid: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error starting transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	chirp, err := qtx.GetChirpByIdForUpdate(r.Context(), chirpID)
+	if err != nil {
+		errMsg := "Error getting chirp by ID: Chirp not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	if userID != chirp.UserID {
+		errMsg := "User forbidden for this action"
+		respondWithError(w, http.StatusForbidden, errMsg)
+		return
+	}
+
+	if !chirp.DeletedAt.Valid {
+		errMsg := "Chirp is not deleted"
+		respondWithError(w, http.StatusConflict, errMsg)
+		return
+	}
+
+	if chirp.RechirpOf.Valid {
+		errMsg := "Rechirps are restored along with the chirp they share"
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	if chirp.PurgedAt.Valid || time.Since(chirp.DeletedAt.Time) > cfg.trashRetention {
+		errMsg := fmt.Sprintf("Chirps can only be restored within %v of being deleted", cfg.trashRetention)
+		respondWithError(w, http.StatusGone, errMsg)
+		return
+	}
+
+	err = qtx.RestoreChirp(r.Context(), database.RestoreChirpParams{
+		ID:        chirp.ID,
+		DeletedAt: chirp.DeletedAt.Time,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error restoring chirp: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		errMsg := fmt.Sprintf("Error committing transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	chirp.DeletedAt = sql.NullTime{}
+	out, err := cfg.chirpToResponse(r.Context(), chirp, userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error building chirp response: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	cfg.publishChirpEvent(streamEventChirpRestored, chirp, out)
+
+	respondWithJSON(w, http.StatusOK, out)
+}
+
+func (cfg *apiConfig) runTrashPurger() {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := cfg.purgeExpiredTrash(context.Background(), time.Now().UTC().Add(-cfg.trashRetention))
+		if err != nil {
+			log.Printf("Error purging trash: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d deleted chirps", purged)
+		}
+		<-ticker.C
+	}
+}
+
+// purgeExpiredTrash permanently removes chirps deleted before cutoff.
+// Chirps that replies or quotes still point at keep their row, with their
+// content and everything derived from it wiped, so those threads keep
+// their shape. Once every reference has itself been purged, a later run
+// deletes the row.
+func (cfg *apiConfig) purgeExpiredTrash(ctx context.Context, cutoff time.Time) (int, error) {
+	total := 0
+	for {
+		deleted, err := cfg.database.DeleteExpiredChirps(ctx, database.DeleteExpiredChirpsParams{
+			DeletedBefore: cutoff,
+			PageLimit:     trashPurgeBatchSize,
+		})
+		if err != nil {
+			return total, fmt.Errorf("Error deleting expired chirps: %w", err)
+		}
+		total += int(deleted)
+		if deleted < trashPurgeBatchSize {
+			break
+		}
+	}
+
+	for {
+		purged, err := cfg.purgeExpiredChirpContent(ctx, cutoff)
+		if err != nil {
+			return total, err
+		}
+		total += purged
+		if purged < trashPurgeBatchSize {
+			return total, nil
+		}
+	}
+}
+
+func (cfg *apiConfig) purgeExpiredChirpContent(ctx context.Context, cutoff time.Time) (int, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("Error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	chirpIDs, err := qtx.PurgeExpiredChirps(ctx, database.PurgeExpiredChirpsParams{
+		DeletedBefore: cutoff,
+		PageLimit:     trashPurgeBatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("Error purging expired chirps: %w", err)
+	}
+
+	// Detached uploads are left for the media garbage collector.
+	for _, chirpID := range chirpIDs {
+		err = qtx.DeleteChirpHashtags(ctx, chirpID)
+		if err == nil {
+			err = qtx.DeleteChirpMentions(ctx, chirpID)
+		}
+		if err == nil {
+			err = qtx.DeleteChirpMedia(ctx, chirpID)
+		}
+		if err == nil {
+			err = qtx.DeleteChirpRevisions(ctx, chirpID)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("Error purging chirp %s: %w", chirpID, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("Error committing transaction: %w", err)
+	}
+
+	return len(chirpIDs), nil
+}
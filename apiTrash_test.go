@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestRestoreChirp(t *testing.T) {
+	authorID := uuid.New()
+	retention := 30 * 24 * time.Hour
+
+	deletedAgo := func(d time.Duration) sql.NullTime {
+		return sql.NullTime{Time: time.Now().UTC().Add(-d), Valid: true}
+	}
+
+	type Case struct {
+		name        string
+		userID      uuid.UUID
+		chirp       database.Chirp
+		wantStatus  int
+		wantRestore bool
+	}
+
+	cases := []Case{
+		{
+			name:        "Just deleted",
+			userID:      authorID,
+			chirp:       database.Chirp{DeletedAt: deletedAgo(time.Minute)},
+			wantStatus:  http.StatusOK,
+			wantRestore: true,
+		},
+		{
+			name:        "Near the end of the window",
+			userID:      authorID,
+			chirp:       database.Chirp{DeletedAt: deletedAgo(retention - time.Minute)},
+			wantStatus:  http.StatusOK,
+			wantRestore: true,
+		},
+		{
+			name:       "Past the window",
+			userID:     authorID,
+			chirp:      database.Chirp{DeletedAt: deletedAgo(retention + time.Minute)},
+			wantStatus: http.StatusGone,
+		},
+		{
+			name:       "Already purged",
+			userID:     authorID,
+			chirp:      database.Chirp{DeletedAt: deletedAgo(time.Minute), PurgedAt: deletedAgo(0)},
+			wantStatus: http.StatusGone,
+		},
+		{
+			name:       "Not deleted",
+			userID:     authorID,
+			chirp:      database.Chirp{},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "Another user's chirp",
+			userID:     uuid.New(),
+			chirp:      database.Chirp{DeletedAt: deletedAgo(time.Minute)},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Rechirp",
+			userID:     authorID,
+			chirp:      database.Chirp{DeletedAt: deletedAgo(time.Minute), RechirpOf: uuid.NullUUID{UUID: uuid.New(), Valid: true}},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			cfg.trashRetention = retention
+			fake.stubChirpResponses()
+
+			chirp := c.chirp
+			chirp.ID = uuid.New()
+			chirp.Body = "oops"
+			chirp.UserID = authorID
+			fake.onRows("GetChirpByIdForUpdate", modelRow(chirp))
+			fake.on("RestoreChirp", func(args []driver.Value) ([][]driver.Value, error) {
+				// The deleted_at guard keeps a restore from reviving rechirps
+				// removed separately.
+				if !args[1].(time.Time).Equal(chirp.DeletedAt.Time) {
+					t.Errorf("RestoreChirp() deleted_at = %v, want %v", args[1], chirp.DeletedAt.Time)
+				}
+				return nil, nil
+			})
+
+			sub, _, _ := cfg.chirpStream.Subscribe(0)
+			defer cfg.chirpStream.Unsubscribe(sub)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/chirps/"+chirp.ID.String()+"/restore", nil)
+			req.Header = authHeader(t, c.userID)
+			req.SetPathValue("chirpID", chirp.ID.String())
+			rec := httptest.NewRecorder()
+			cfg.handlerRestoreChirp(rec, req)
+
+			if rec.Code != c.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, c.wantStatus, rec.Body)
+			}
+			restored := fake.called("RestoreChirp") > 0 && fake.commits == 1
+			if restored != c.wantRestore {
+				t.Errorf("Restored = %v, want %v", restored, c.wantRestore)
+			}
+			if announced := len(sub.C) > 0; announced != c.wantRestore {
+				t.Errorf("Published chirp_restored = %v, want %v", announced, c.wantRestore)
+			}
+		})
+	}
+}
+
+func TestPurgeExpiredTrash(t *testing.T) {
+	type Case struct {
+		name       string
+		deletable  int
+		referenced int
+		wantPurged int
+		wantWiped  int
+	}
+
+	cases := []Case{
+		{name: "Nothing expired"},
+		{name: "Unreferenced chirps are deleted", deletable: 3, wantPurged: 3},
+		{name: "Referenced chirps keep their row", referenced: 2, wantPurged: 2, wantWiped: 2},
+		{name: "Several batches", deletable: trashPurgeBatchSize + 1, referenced: trashPurgeBatchSize, wantPurged: 2*trashPurgeBatchSize + 1, wantWiped: trashPurgeBatchSize},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			cutoff := time.Now().UTC().Add(-cfg.trashRetention)
+
+			// Mirrors the purge queries: each call works through at most a
+			// page of what is left.
+			deletable, referenced := c.deletable, c.referenced
+			fake.on("DeleteExpiredChirps", func(args []driver.Value) ([][]driver.Value, error) {
+				if !args[0].(time.Time).Equal(cutoff) {
+					t.Errorf("DeleteExpiredChirps() cutoff = %v, want %v", args[0], cutoff)
+				}
+				n := min(deletable, int(args[1].(int64)))
+				deletable -= n
+				return make([][]driver.Value, n), nil
+			})
+			fake.on("PurgeExpiredChirps", func(args []driver.Value) ([][]driver.Value, error) {
+				n := min(referenced, int(args[1].(int64)))
+				referenced -= n
+				rows := [][]driver.Value{}
+				for i := 0; i < n; i++ {
+					rows = append(rows, []driver.Value{uuid.New().String()})
+				}
+				return rows, nil
+			})
+			for _, name := range []string{"DeleteChirpHashtags", "DeleteChirpMentions", "DeleteChirpMedia", "DeleteChirpRevisions"} {
+				fake.on(name, execOK(0))
+			}
+
+			purged, err := cfg.purgeExpiredTrash(context.Background(), cutoff)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if purged != c.wantPurged {
+				t.Errorf("purgeExpiredTrash() = %d, want %d", purged, c.wantPurged)
+			}
+			if wiped := fake.called("DeleteChirpRevisions"); wiped != c.wantWiped {
+				t.Errorf("Wiped %d chirps, want %d", wiped, c.wantWiped)
+			}
+			if deletable != 0 || referenced != 0 {
+				t.Errorf("Left %d deletable and %d referenced chirps", deletable, referenced)
+			}
+		})
+	}
+}
@@ -1,16 +1,36 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/delroscol98/chirpy/internal/auth"
 	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/delroscol98/chirpy/internal/entities"
 )
 
+func databaseUserToPublicResponse(user database.User) PublicUserResponseBody {
+	out := PublicUserResponseBody{
+		ID:          user.ID,
+		CreatedAt:   user.CreatedAt,
+		Handle:      user.Handle.String,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarUrl,
+		IsChirpyRed: user.IsChirpyRed,
+	}
+	if user.PinnedChirpID.Valid {
+		out.PinnedChirpID = &user.PinnedChirpID.UUID
+	}
+
+	return out
+}
+
 func (cfg *apiConfig) handlerUpdatedUserEmailPassword(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 	data, err := io.ReadAll(r.Body)
@@ -35,7 +55,7 @@ func (cfg *apiConfig) handlerUpdatedUserEmailPassword(w http.ResponseWriter, r *
 		return
 	}
 
-	userID, err := auth.ValidateJWT(accessToken, cfg.secret)
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error validating access token: %v", err)
 		respondWithError(w, http.StatusUnauthorized, errMsg)
@@ -49,16 +69,73 @@ func (cfg *apiConfig) handlerUpdatedUserEmailPassword(w http.ResponseWriter, r *
 		return
 	}
 
-	user, err := cfg.database.UpdateUserEmailPassword(r.Context(), database.UpdateUserEmailPasswordParams{
+	if req.Handle != "" {
+		err = validateHandle(req.Handle)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	// Both updates share a transaction so a taken handle leaves the email
+	// and password unchanged too.
+	tx, err := cfg.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error starting transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	user, err := qtx.UpdateUserEmailPassword(r.Context(), database.UpdateUserEmailPasswordParams{
 		Email:          req.Email,
 		HashedPassword: hashedPw,
 		ID:             userID,
 	})
+	if isUniqueViolation(err) {
+		errMsg := "Email is already taken"
+		respondWithError(w, http.StatusConflict, errMsg)
+		return
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("Error updating email and password: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	if req.Handle != "" {
+		user, err = qtx.UpdateUserHandle(r.Context(), database.UpdateUserHandleParams{
+			Handle: sql.NullString{String: req.Handle, Valid: true},
+			ID:     userID,
+		})
+		if isUniqueViolation(err) {
+			errMsg := "Handle is already taken"
+			respondWithError(w, http.StatusConflict, errMsg)
+			return
+		}
+		if err != nil {
+			errMsg := fmt.Sprintf("Error updating handle: %v", err)
+			respondWithError(w, http.StatusInternalServerError, errMsg)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		errMsg := fmt.Sprintf("Error committing transaction: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
 
 	respondWithJSON(w, http.StatusOK, UserResponseBody{
 		ID:          user.ID,
 		UpdatedAt:   time.Now(),
 		Email:       user.Email,
+		Handle:      user.Handle.String,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarUrl,
 		IsChirpyRed: user.IsChirpyRed,
 	})
 }
@@ -81,12 +158,26 @@ func (cfg *apiConfig) handlerCreateUsers(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if params.Handle != "" {
+		err = validateHandle(params.Handle)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
 	hashedPw, err := auth.HashPassword(params.Password)
 
 	user, err := cfg.database.CreateUser(r.Context(), database.CreateUserParams{
 		Email:          params.Email,
 		HashedPassword: hashedPw,
+		Handle:         sql.NullString{String: params.Handle, Valid: params.Handle != ""},
 	})
+	if isUniqueViolation(err) {
+		errMsg := "Email or handle is already taken"
+		respondWithError(w, http.StatusConflict, errMsg)
+		return
+	}
 	if err != nil {
 		errMsg := fmt.Sprintf("Error creating new user: %v", err)
 		respondWithError(w, http.StatusInternalServerError, errMsg)
@@ -98,6 +189,138 @@ func (cfg *apiConfig) handlerCreateUsers(w http.ResponseWriter, r *http.Request)
 		CreatedAt:   user.CreatedAt,
 		UpdatedAt:   user.UpdatedAt,
 		Email:       user.Email,
+		Handle:      user.Handle.String,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarUrl,
+		IsChirpyRed: user.IsChirpyRed,
+	})
+}
+
+func (cfg *apiConfig) handlerGetUserByHandle(w http.ResponseWriter, r *http.Request) {
+	handle := entities.NormalizeHandle(r.PathValue("handle"))
+	if !entities.ValidHandle(handle) {
+		errMsg := "Error getting user by handle: User not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	user, err := cfg.database.GetUserByHandle(r.Context(), handle)
+	if err != nil {
+		errMsg := "Error getting user by handle: User not found"
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	counts, err := cfg.database.GetUserFollowCounts(r.Context(), user.ID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting follow counts: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, UserProfileResponseBody{
+		PublicUserResponseBody: databaseUserToPublicResponse(user),
+		FollowerCount:          counts.FollowerCount,
+		FollowingCount:         counts.FollowingCount,
+	})
+}
+
+func (cfg *apiConfig) handlerUpdateUserProfile(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting bearer token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error reading request body: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+	params := UserProfileRequestBody{}
+	err = json.Unmarshal(data, &params)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error unmarshalling data: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+
+	user, err := cfg.database.GetUserById(r.Context(), userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting user by ID: %v", err)
+		respondWithError(w, http.StatusNotFound, errMsg)
+		return
+	}
+
+	handle := user.Handle
+	if params.Handle != nil {
+		handle = sql.NullString{String: *params.Handle, Valid: *params.Handle != ""}
+		if handle.Valid {
+			err = validateHandle(handle.String)
+			if err != nil {
+				respondWithError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+	}
+	displayName := user.DisplayName
+	if params.DisplayName != nil {
+		displayName = strings.TrimSpace(*params.DisplayName)
+	}
+	bio := user.Bio
+	if params.Bio != nil {
+		bio = strings.TrimSpace(*params.Bio)
+	}
+	avatarURL := user.AvatarUrl
+	if params.AvatarURL != nil {
+		avatarURL = strings.TrimSpace(*params.AvatarURL)
+	}
+
+	err = validateProfile(displayName, bio, avatarURL)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err = cfg.database.UpdateUserProfile(r.Context(), database.UpdateUserProfileParams{
+		Handle:      handle,
+		DisplayName: displayName,
+		Bio:         bio,
+		AvatarUrl:   avatarURL,
+		ID:          userID,
+	})
+	if isUniqueViolation(err) {
+		errMsg := "Handle is already taken"
+		respondWithError(w, http.StatusConflict, errMsg)
+		return
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("Error updating profile: %v", err)
+		respondWithError(w, http.StatusInternalServerError, errMsg)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, UserResponseBody{
+		ID:          user.ID,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+		Email:       user.Email,
+		Handle:      user.Handle.String,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarUrl,
 		IsChirpyRed: user.IsChirpyRed,
 	})
 }
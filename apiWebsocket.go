@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/auth"
+	"github.com/delroscol98/chirpy/internal/entities"
+	"github.com/delroscol98/chirpy/internal/stream"
+	"github.com/delroscol98/chirpy/internal/websocket"
+	"github.com/google/uuid"
+)
+
+const (
+	wsPingInterval = 30 * time.Second
+	wsIdleTimeout  = 75 * time.Second
+
+	wsChannelTimeline      = "timeline"
+	wsChannelNotifications = "notifications"
+)
+
+func (cfg *apiConfig) handlerWebsocket(w http.ResponseWriter, r *http.Request) {
+	// Browsers cannot set headers on a WebSocket handshake, so the access
+	// token may also be passed as a query parameter.
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		accessToken = r.URL.Query().Get("access_token")
+	}
+	if accessToken == "" {
+		errMsg := "Error getting bearer token: No bearer found"
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	userID, expiresAt, err := auth.ValidateJWTWithExpiry(accessToken, cfg.secret)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	err = cfg.checkAccountActive(r.Context(), userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error validating access token: %v", err)
+		respondWithError(w, http.StatusUnauthorized, errMsg)
+		return
+	}
+
+	hidden, err := cfg.loadHiddenAuthors(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error upgrading connection: %v", err)
+		respondWithError(w, http.StatusBadRequest, errMsg)
+		return
+	}
+	defer conn.Close()
+	conn.IdleTimeout = wsIdleTimeout
+
+	client := &wsClient{
+		cfg:       cfg,
+		conn:      conn,
+		userID:    userID,
+		expiresAt: expiresAt,
+		channels:  map[string]bool{},
+		followees: map[uuid.UUID]bool{},
+		hidden:    hidden,
+	}
+	client.run()
+}
+
+// wsClient is one authenticated connection. All writes except control
+// replies happen on the run goroutine, so its state needs no locking.
+type wsClient struct {
+	cfg       *apiConfig
+	conn      *websocket.Conn
+	userID    uuid.UUID
+	expiresAt time.Time
+	channels  map[string]bool
+	followees map[uuid.UUID]bool
+	hidden    map[uuid.UUID]bool
+}
+
+func (c *wsClient) run() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chirpSub, _, _ := c.cfg.chirpStream.Subscribe(0)
+	defer c.cfg.chirpStream.Unsubscribe(chirpSub)
+	userSub, _, _ := c.cfg.userStream.Subscribe(0, notificationsTopic(c.userID), followsTopic(c.userID), accountTopic(c.userID))
+	defer c.cfg.userStream.Unsubscribe(userSub)
+
+	incoming := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			_, data, err := c.conn.ReadMessage()
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case incoming <- data:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+	expiry := time.NewTimer(time.Until(c.expiresAt))
+	defer expiry.Stop()
+
+	for {
+		var err error
+		select {
+		case <-readErr:
+			return
+		case data := <-incoming:
+			err = c.handleMessage(ctx, data, expiry)
+		case event, ok := <-chirpSub.C:
+			if !ok {
+				c.conn.WriteClose(websocket.CloseTryAgainLater, "client too slow")
+				return
+			}
+			err = c.deliverChirpEvent(event)
+		case event, ok := <-userSub.C:
+			if !ok {
+				c.conn.WriteClose(websocket.CloseTryAgainLater, "client too slow")
+				return
+			}
+			err = c.deliverUserEvent(ctx, event)
+		case <-ping.C:
+			err = c.conn.WriteMessage(websocket.PingMessage, nil)
+		case <-expiry.C:
+			c.conn.WriteClose(websocket.ClosePolicyViolation, "token expired")
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (c *wsClient) handleMessage(ctx context.Context, data []byte, expiry *time.Timer) error {
+	msg := WebsocketClientMessage{}
+	err := json.Unmarshal(data, &msg)
+	if err != nil {
+		return c.sendError(fmt.Sprintf("Error unmarshalling data: %v", err))
+	}
+
+	switch msg.Action {
+	case "subscribe":
+		channel, err := c.subscribe(ctx, msg.Channel)
+		if err != nil {
+			return c.sendError(err.Error())
+		}
+		return c.send(WebsocketServerMessage{Type: "subscribed", Channel: channel})
+	case "unsubscribe":
+		channel, err := normalizeWebsocketChannel(msg.Channel)
+		if err != nil {
+			return c.sendError(err.Error())
+		}
+		delete(c.channels, channel)
+		return c.send(WebsocketServerMessage{Type: "unsubscribed", Channel: channel})
+	case "authenticate":
+		// Clients refresh their access token in place to keep the
+		// connection open past the original token's expiry.
+		userID, expiresAt, err := auth.ValidateJWTWithExpiry(msg.Token, c.cfg.secret)
+		if err != nil || userID != c.userID {
+			return c.sendError("Error validating access token")
+		}
+		err = c.cfg.checkAccountActive(ctx, userID)
+		if err != nil {
+			return c.sendError(fmt.Sprintf("Error validating access token: %v", err))
+		}
+		c.expiresAt = expiresAt
+		expiry.Reset(time.Until(expiresAt))
+		return c.send(WebsocketServerMessage{Type: "authenticated"})
+	default:
+		return c.sendError(fmt.Sprintf("Unknown action %q", msg.Action))
+	}
+}
+
+func (c *wsClient) subscribe(ctx context.Context, rawChannel string) (string, error) {
+	channel, err := normalizeWebsocketChannel(rawChannel)
+	if err != nil {
+		return "", err
+	}
+
+	if channel == wsChannelTimeline {
+		err = c.loadFollowees(ctx)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	c.channels[channel] = true
+	return channel, nil
+}
+
+func (c *wsClient) loadFollowees(ctx context.Context) error {
+	followeeIDs, err := c.cfg.database.GetFolloweeIds(ctx, c.userID)
+	if err != nil {
+		return fmt.Errorf("Error getting followed users: %w", err)
+	}
+
+	c.followees = make(map[uuid.UUID]bool, len(followeeIDs))
+	for _, id := range followeeIDs {
+		c.followees[id] = true
+	}
+
+	return nil
+}
+
+// normalizeWebsocketChannel validates a channel name and returns its
+// canonical form: "timeline", "notifications", "user:<id>" or
+// "hashtag:<tag>".
+func normalizeWebsocketChannel(channel string) (string, error) {
+	switch {
+	case channel == wsChannelTimeline || channel == wsChannelNotifications:
+		return channel, nil
+	case strings.HasPrefix(channel, "user:"):
+		id, err := uuid.Parse(strings.TrimPrefix(channel, "user:"))
+		if err != nil {
+			return "", fmt.Errorf("Error parsing string uuid: %w", err)
+		}
+		return userTopic(id), nil
+	case strings.HasPrefix(channel, "hashtag:"):
+		tag := entities.NormalizeHashtag(strings.TrimPrefix(channel, "hashtag:"))
+		if tag == "" {
+			return "", errors.New("Hashtag channel requires a tag")
+		}
+		return hashtagTopic(tag), nil
+	default:
+		return "", fmt.Errorf("Unknown channel %q", channel)
+	}
+}
+
+// deliverChirpEvent sends the event once for every subscribed channel it
+// matches, so clients can route messages by channel alone. Chirps from
+// blocked or muted users are dropped on every channel.
+func (c *wsClient) deliverChirpEvent(event stream.Event) error {
+	if hidesChirpEvent(event, c.hidden) {
+		return nil
+	}
+
+	for channel := range c.channels {
+		matches := false
+		switch channel {
+		case wsChannelNotifications:
+		case wsChannelTimeline:
+			for followeeID := range c.followees {
+				if event.HasTopic(userTopic(followeeID)) {
+					matches = true
+					break
+				}
+			}
+		default:
+			matches = event.HasTopic(channel)
+		}
+		if !matches {
+			continue
+		}
+
+		err := c.sendEvent(channel, event)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// errAccountRestricted ends the run loop once the socket has been closed
+// because the account was suspended or banned.
+var errAccountRestricted = errors.New("Account is restricted")
+
+func (c *wsClient) deliverUserEvent(ctx context.Context, event stream.Event) error {
+	switch event.Type {
+	case streamEventAccountRestricted:
+		if !event.HasTopic(accountTopic(c.userID)) {
+			return nil
+		}
+		c.conn.WriteClose(websocket.ClosePolicyViolation, "account restricted")
+		return errAccountRestricted
+	case streamEventNotification:
+		if !c.channels[wsChannelNotifications] || !event.HasTopic(notificationsTopic(c.userID)) {
+			return nil
+		}
+		return c.sendEvent(wsChannelNotifications, event)
+	case streamEventFollowsChanged:
+		if !event.HasTopic(followsTopic(c.userID)) {
+			return nil
+		}
+		hidden, err := c.cfg.loadHiddenAuthors(ctx, c.userID)
+		if err != nil {
+			return c.sendError(err.Error())
+		}
+		c.hidden = hidden
+		if !c.channels[wsChannelTimeline] {
+			return nil
+		}
+		err = c.loadFollowees(ctx)
+		if err != nil {
+			return c.sendError(err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (c *wsClient) sendEvent(channel string, event stream.Event) error {
+	return c.send(WebsocketServerMessage{
+		Type:    "event",
+		Channel: channel,
+		Event:   event.Type,
+		Data:    event.Data,
+	})
+}
+
+func (c *wsClient) sendError(errMsg string) error {
+	return c.send(WebsocketServerMessage{Type: "error", Error: errMsg})
+}
+
+func (c *wsClient) send(msg WebsocketServerMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/delroscol98/chirpy/internal/websocket"
+	"github.com/google/uuid"
+)
+
+// testWebsocket is a minimal client: it performs the handshake and reads
+// whole unfragmented frames, which is all the server sends.
+type testWebsocket struct {
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+func dialTestWebsocket(t *testing.T, server *httptest.Server, header http.Header) *testWebsocket {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/ws", nil)
+	req.Header = header
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	err = req.Write(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Handshake status = %d, want %d", resp.StatusCode, http.StatusSwitchingProtocols)
+	}
+
+	return &testWebsocket{conn: conn, br: br}
+}
+
+func (ws *testWebsocket) send(t *testing.T, msg WebsocketClientMessage) {
+	t.Helper()
+
+	data, _ := json.Marshal(msg)
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{0x80 | websocket.TextMessage, 0x80 | byte(len(data))}
+	frame = append(frame, mask...)
+	for i, b := range data {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := ws.conn.Write(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (ws *testWebsocket) read(t *testing.T) (int, []byte) {
+	t.Helper()
+
+	header := make([]byte, 2)
+	_, err := io.ReadFull(ws.br, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		extended := make([]byte, 2)
+		io.ReadFull(ws.br, extended)
+		length = int(binary.BigEndian.Uint16(extended))
+	}
+	payload := make([]byte, length)
+	_, err = io.ReadFull(ws.br, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return int(header[0] & 0x0F), payload
+}
+
+func TestWebsocketClosesOnBan(t *testing.T) {
+	cfg, fake := newTestConfig(t)
+	adminID := uuid.New()
+	userID := uuid.New()
+
+	fake.onRows("GetUserById", modelRow(database.User{ID: adminID, IsAdmin: true, AccountStatus: accountStatusActive}))
+	fake.onRows("GetUserByIdForUpdate", modelRow(database.User{ID: userID, AccountStatus: accountStatusActive}))
+	fake.on("SetUserAccountStatus", execOK(1))
+	fake.on("RevokeUserRefreshTokens", execOK(1))
+	fake.on("DeletePendingScheduledChirps", execOK(0))
+	fake.onRows("CreateModerationAction", modelRow(database.ModerationAction{
+		ID:           uuid.New(),
+		Action:       moderationActionBanUser,
+		TargetUserID: uuid.NullUUID{UUID: userID, Valid: true},
+		Reason:       "abuse",
+	}))
+	fake.on("GetFolloweeIds", noRows)
+
+	server := httptest.NewServer(http.HandlerFunc(cfg.handlerWebsocket))
+	defer server.Close()
+	ws := dialTestWebsocket(t, server, authHeader(t, userID))
+
+	// Waiting for the reply means the client is subscribed to the user
+	// stream before the ban is published.
+	ws.send(t, WebsocketClientMessage{Action: "subscribe", Channel: wsChannelTimeline})
+	opcode, data := ws.read(t)
+	reply := WebsocketServerMessage{}
+	json.Unmarshal(data, &reply)
+	if opcode != websocket.TextMessage || reply.Type != "subscribed" {
+		t.Fatalf("Got opcode %d %s, want a subscribed reply", opcode, data)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/"+userID.String()+"/ban", strings.NewReader(`{"reason":"abuse"}`))
+	req.Header = authHeader(t, adminID)
+	req.SetPathValue("userID", userID.String())
+	rec := httptest.NewRecorder()
+	cfg.handlerAdminBanUser(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Ban status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body)
+	}
+
+	opcode, data = ws.read(t)
+	if opcode != websocket.CloseMessage {
+		t.Fatalf("Got opcode %d %s, want a close frame", opcode, data)
+	}
+	if len(data) < 2 || int(binary.BigEndian.Uint16(data)) != websocket.ClosePolicyViolation {
+		t.Errorf("Close payload = %q, want code %d", data, websocket.ClosePolicyViolation)
+	}
+}
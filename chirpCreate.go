@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// pendingChirp is a chirp that has passed every check in prepareChirp but
+// has not been written yet.
+type pendingChirp struct {
+	Body           string
+	ParentID       uuid.NullUUID
+	RootID         uuid.NullUUID
+	ParentAuthorID uuid.UUID
+	QuoteOf        uuid.NullUUID
+	Media          []ChirpMediaRequestBody
+	Poll           *PollRequestBody
+}
+
+// prepareChirp applies the rules for posting a chirp without writing
+// anything, so deferred chirps can be vetted both when they are saved and
+// again when they are finally published. On failure it returns the status
+// code to respond with.
+func (cfg *apiConfig) prepareChirp(ctx context.Context, qtx *database.Queries, userID uuid.UUID, params ChirpRequestBody) (pendingChirp, int, error) {
+	body, err := cfg.validateChirpBody(params.Body)
+	if err != nil {
+		return pendingChirp{}, http.StatusBadRequest, err
+	}
+
+	err = validateChirpMedia(params.Media)
+	if err != nil {
+		return pendingChirp{}, http.StatusBadRequest, err
+	}
+
+	pending := pendingChirp{Body: body, Media: params.Media}
+	if params.Poll != nil {
+		poll, err := validatePoll(*params.Poll, time.Now())
+		if err != nil {
+			return pendingChirp{}, http.StatusBadRequest, err
+		}
+		pending.Poll = &poll
+	}
+
+	if params.InReplyTo != nil {
+		parent, err := cfg.getLiveChirp(ctx, *params.InReplyTo)
+		if err != nil {
+			return pendingChirp{}, http.StatusNotFound, errors.New("Chirp being replied to does not exist")
+		}
+
+		blocked, err := cfg.isBlockedBetween(ctx, userID, parent.UserID)
+		if err != nil {
+			return pendingChirp{}, http.StatusInternalServerError, err
+		}
+		if blocked {
+			return pendingChirp{}, http.StatusForbidden, errors.New("Cannot reply to a blocked user")
+		}
+
+		pending.ParentID = uuid.NullUUID{UUID: parent.ID, Valid: true}
+		pending.ParentAuthorID = parent.UserID
+		pending.RootID = parent.RootID
+		if !pending.RootID.Valid {
+			pending.RootID = uuid.NullUUID{UUID: parent.ID, Valid: true}
+		}
+	}
+
+	if params.QuoteOf != nil {
+		if body == "" {
+			return pendingChirp{}, http.StatusBadRequest, errors.New("Quote chirps must have a body")
+		}
+
+		quoted, err := cfg.getLiveChirp(ctx, *params.QuoteOf)
+		if err != nil {
+			return pendingChirp{}, http.StatusNotFound, errors.New("Chirp being quoted does not exist")
+		}
+
+		blocked, err := cfg.isBlockedBetween(ctx, userID, quoted.UserID)
+		if err != nil {
+			return pendingChirp{}, http.StatusInternalServerError, err
+		}
+		if blocked {
+			return pendingChirp{}, http.StatusForbidden, errors.New("Cannot quote a blocked user's chirp")
+		}
+
+		pending.QuoteOf = uuid.NullUUID{UUID: quoted.ID, Valid: true}
+	}
+
+	code, err := checkChirpMedia(ctx, qtx, userID, params.Media)
+	if err != nil {
+		return pendingChirp{}, code, err
+	}
+
+	return pending, http.StatusOK, nil
+}
+
+// insertChirp writes a prepared chirp along with its attachments, poll,
+// hashtags and mentions, queueing notifications on batch.
+func (cfg *apiConfig) insertChirp(ctx context.Context, qtx *database.Queries, userID uuid.UUID, pending pendingChirp, notifications *notificationBatch) (database.Chirp, error) {
+	chirp, err := qtx.CreateChirp(ctx, database.CreateChirpParams{
+		Body:     pending.Body,
+		UserID:   userID,
+		ParentID: pending.ParentID,
+		RootID:   pending.RootID,
+		QuoteOf:  pending.QuoteOf,
+	})
+	if err != nil {
+		return database.Chirp{}, fmt.Errorf("Error creating chirp: %w", err)
+	}
+
+	err = attachChirpMedia(ctx, qtx, chirp.ID, pending.Media)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+
+	if pending.Poll != nil {
+		_, err = qtx.CreatePoll(ctx, database.CreatePollParams{
+			ChirpID:  chirp.ID,
+			ClosesAt: pending.Poll.ClosesAt,
+		})
+		if err != nil {
+			return database.Chirp{}, fmt.Errorf("Error creating poll: %w", err)
+		}
+
+		for position, option := range pending.Poll.Options {
+			err = qtx.AddPollOption(ctx, database.AddPollOptionParams{
+				ChirpID:  chirp.ID,
+				Position: int32(position),
+				Label:    option,
+			})
+			if err != nil {
+				return database.Chirp{}, fmt.Errorf("Error adding poll option: %w", err)
+			}
+		}
+	}
+
+	err = cfg.indexChirpEntities(ctx, qtx, chirp, notifications)
+	if err != nil {
+		return database.Chirp{}, fmt.Errorf("Error indexing chirp: %w", err)
+	}
+
+	if pending.ParentID.Valid {
+		err = cfg.notify(ctx, qtx, notifications, pending.ParentAuthorID, userID, notificationTypeReply, uuid.NullUUID{UUID: chirp.ID, Valid: true})
+		if err != nil {
+			return database.Chirp{}, err
+		}
+	}
+
+	return chirp, nil
+}
+
+// announceChirp publishes the events for a chirp whose transaction has
+// committed and returns the chirp as its author sees it.
+func (cfg *apiConfig) announceChirp(ctx context.Context, chirp database.Chirp, notifications notificationBatch) (ChirpResponseBody, error) {
+	cfg.publishNotifications(notifications)
+
+	out, err := cfg.chirpToResponse(ctx, chirp, chirp.UserID)
+	if err != nil {
+		return ChirpResponseBody{}, fmt.Errorf("Error building chirp response: %w", err)
+	}
+
+	cfg.publishChirpEvent(streamEventChirpCreated, chirp, out)
+
+	return out, nil
+}
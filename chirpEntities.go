@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/delroscol98/chirpy/internal/entities"
+	"github.com/google/uuid"
+)
+
+// indexChirpEntities replaces the hashtags and mentions linked to chirp
+// with the ones in its current body, so it serves both new and edited
+// chirps. Only users who were not already mentioned are notified.
+func (cfg *apiConfig) indexChirpEntities(ctx context.Context, qtx *database.Queries, chirp database.Chirp, notifications *notificationBatch) error {
+	err := qtx.DeleteChirpHashtags(ctx, chirp.ID)
+	if err != nil {
+		return fmt.Errorf("Error clearing chirp hashtags: %w", err)
+	}
+
+	for _, tag := range entities.ExtractHashtags(chirp.Body) {
+		hashtag, err := qtx.UpsertHashtag(ctx, tag)
+		if err != nil {
+			return fmt.Errorf("Error saving hashtag %q: %w", tag, err)
+		}
+
+		err = qtx.AddChirpHashtag(ctx, database.AddChirpHashtagParams{
+			ChirpID:   chirp.ID,
+			HashtagID: hashtag.ID,
+			CreatedAt: chirp.CreatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("Error linking hashtag %q: %w", tag, err)
+		}
+	}
+
+	return cfg.indexChirpMentions(ctx, qtx, chirp, notifications)
+}
+
+func (cfg *apiConfig) indexChirpMentions(ctx context.Context, qtx *database.Queries, chirp database.Chirp, notifications *notificationBatch) error {
+	previouslyMentioned, err := qtx.GetChirpMentionedUserIds(ctx, chirp.ID)
+	if err != nil {
+		return fmt.Errorf("Error getting previous mentions: %w", err)
+	}
+	alreadyNotified := make(map[uuid.UUID]bool, len(previouslyMentioned))
+	for _, userID := range previouslyMentioned {
+		alreadyNotified[userID] = true
+	}
+
+	err = qtx.DeleteChirpMentions(ctx, chirp.ID)
+	if err != nil {
+		return fmt.Errorf("Error clearing chirp mentions: %w", err)
+	}
+
+	mentions := entities.ExtractMentions(chirp.Body)
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	handles := make([]string, 0, len(mentions))
+	for _, mention := range mentions {
+		handles = append(handles, entities.NormalizeHandle(mention.Handle))
+	}
+
+	users, err := qtx.GetUsersByHandles(ctx, handles)
+	if err != nil {
+		return fmt.Errorf("Error resolving mentioned handles: %w", err)
+	}
+	userByHandle := make(map[string]database.User, len(users))
+	for _, user := range users {
+		userByHandle[entities.NormalizeHandle(user.Handle.String)] = user
+	}
+
+	for _, mention := range mentions {
+		user, ok := userByHandle[entities.NormalizeHandle(mention.Handle)]
+		if !ok {
+			continue
+		}
+
+		err = qtx.AddChirpMention(ctx, database.AddChirpMentionParams{
+			ChirpID:     chirp.ID,
+			UserID:      user.ID,
+			StartOffset: int32(mention.Start),
+			EndOffset:   int32(mention.End),
+		})
+		if err != nil {
+			return fmt.Errorf("Error saving mention of %q: %w", mention.Handle, err)
+		}
+
+		if alreadyNotified[user.ID] {
+			continue
+		}
+		alreadyNotified[user.ID] = true
+
+		err = cfg.notify(ctx, qtx, notifications, user.ID, chirp.UserID, notificationTypeMention, uuid.NullUUID{UUID: chirp.ID, Valid: true})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
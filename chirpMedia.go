@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	// Uploads that are still unattached after this long are deleted.
+	mediaOrphanAge   = 24 * time.Hour
+	mediaGCInterval  = time.Hour
+	mediaGCBatchSize = 100
+)
+
+// checkChirpMedia verifies that every attachment is an unused chirp
+// upload owned by userID. The uploads stay locked for the rest of qtx's
+// transaction so the garbage collector cannot delete one while it is being
+// attached. On failure it returns the status code to respond with.
+func checkChirpMedia(ctx context.Context, qtx *database.Queries, userID uuid.UUID, attachments []ChirpMediaRequestBody) (int, error) {
+	if len(attachments) == 0 {
+		return http.StatusOK, nil
+	}
+
+	mediaIDs := make([]uuid.UUID, 0, len(attachments))
+	for _, attachment := range attachments {
+		mediaIDs = append(mediaIDs, attachment.ID)
+	}
+
+	uploads, err := qtx.GetMediaUploadsByIdsForUpdate(ctx, mediaIDs)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("Error getting media: %w", err)
+	}
+	uploadByID := make(map[uuid.UUID]database.MediaUpload, len(uploads))
+	for _, upload := range uploads {
+		uploadByID[upload.ID] = upload
+	}
+
+	for _, attachment := range attachments {
+		upload, ok := uploadByID[attachment.ID]
+		if !ok {
+			return http.StatusBadRequest, fmt.Errorf("Media %s does not exist", attachment.ID)
+		}
+		if upload.UserID != userID {
+			return http.StatusForbidden, fmt.Errorf("Media %s belongs to another user", attachment.ID)
+		}
+		if upload.Purpose != mediaPurposeChirp {
+			return http.StatusBadRequest, fmt.Errorf("Media %s cannot be attached to a chirp", attachment.ID)
+		}
+	}
+
+	usedIDs, err := qtx.GetUsedMediaIds(ctx, mediaIDs)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("Error checking media: %w", err)
+	}
+	if len(usedIDs) > 0 {
+		return http.StatusConflict, fmt.Errorf("Media %s is already attached to a chirp", usedIDs[0])
+	}
+
+	return http.StatusOK, nil
+}
+
+// attachChirpMedia links attachments already vetted by checkChirpMedia to
+// a chirp, in request order.
+func attachChirpMedia(ctx context.Context, qtx *database.Queries, chirpID uuid.UUID, attachments []ChirpMediaRequestBody) error {
+	for position, attachment := range attachments {
+		err := qtx.AddChirpMedia(ctx, database.AddChirpMediaParams{
+			ChirpID:  chirpID,
+			MediaID:  attachment.ID,
+			Position: int32(position),
+			AltText:  strings.TrimSpace(attachment.AltText),
+		})
+		if err != nil {
+			return fmt.Errorf("Error attaching media: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (cfg *apiConfig) runMediaGC() {
+	ticker := time.NewTicker(mediaGCInterval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := cfg.collectOrphanedMedia(context.Background(), time.Now().Add(-mediaOrphanAge))
+		if err != nil {
+			log.Printf("Error collecting orphaned media: %v", err)
+		} else if deleted > 0 {
+			log.Printf("Deleted %d orphaned media uploads", deleted)
+		}
+		<-ticker.C
+	}
+}
+
+// collectOrphanedMedia deletes uploads created before cutoff that are not
+// attached, waiting on a scheduled chirp or backing an avatar. Rows are
+// removed first and blobs only once that has committed, so a failure never
+// leaves a row pointing at a missing blob.
+func (cfg *apiConfig) collectOrphanedMedia(ctx context.Context, cutoff time.Time) (int, error) {
+	total := 0
+	for {
+		tx, err := cfg.db.BeginTx(ctx, nil)
+		if err != nil {
+			return total, fmt.Errorf("Error starting transaction: %w", err)
+		}
+		qtx := cfg.database.WithTx(tx)
+
+		uploads, err := qtx.GetOrphanedMediaUploads(ctx, database.GetOrphanedMediaUploadsParams{
+			CreatedAt: cutoff,
+			Limit:     mediaGCBatchSize,
+		})
+		if err != nil {
+			tx.Rollback()
+			return total, fmt.Errorf("Error getting orphaned media: %w", err)
+		}
+
+		for _, upload := range uploads {
+			err = qtx.DeleteMediaUpload(ctx, upload.ID)
+			if err != nil {
+				tx.Rollback()
+				return total, fmt.Errorf("Error deleting media upload: %w", err)
+			}
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			return total, fmt.Errorf("Error committing transaction: %w", err)
+		}
+
+		for _, upload := range uploads {
+			for _, key := range []string{upload.StorageKey, upload.ThumbnailKey} {
+				err = cfg.storage.Delete(ctx, key)
+				if err != nil {
+					log.Printf("Error deleting blob %q: %v", key, err)
+				}
+			}
+		}
+
+		total += len(uploads)
+		if len(uploads) < mediaGCBatchSize {
+			return total, nil
+		}
+	}
+}
+
+func (cfg *apiConfig) chirpMediaByChirp(ctx context.Context, chirpIDs []uuid.UUID) (map[uuid.UUID][]ChirpMediaResponseBody, error) {
+	rows, err := cfg.database.GetChirpMedia(ctx, chirpIDs)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting chirp media: %w", err)
+	}
+
+	mediaByChirp := make(map[uuid.UUID][]ChirpMediaResponseBody, len(chirpIDs))
+	for _, row := range rows {
+		mediaByChirp[row.ChirpID] = append(mediaByChirp[row.ChirpID], ChirpMediaResponseBody{
+			ID:           row.MediaID,
+			ContentType:  row.ContentType,
+			URL:          cfg.storage.URL(row.StorageKey),
+			ThumbnailURL: cfg.storage.URL(row.ThumbnailKey),
+			Width:        row.Width,
+			Height:       row.Height,
+			AltText:      row.AltText,
+		})
+	}
+
+	return mediaByChirp, nil
+}
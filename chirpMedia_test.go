@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestCheckChirpMedia(t *testing.T) {
+	ownerID := uuid.New()
+	upload := database.MediaUpload{ID: uuid.New(), UserID: ownerID, Purpose: mediaPurposeChirp}
+	other := database.MediaUpload{ID: uuid.New(), UserID: uuid.New(), Purpose: mediaPurposeChirp}
+	avatar := database.MediaUpload{ID: uuid.New(), UserID: ownerID, Purpose: mediaPurposeAvatar}
+
+	type Case struct {
+		name     string
+		mediaID  uuid.UUID
+		used     bool
+		wantCode int
+	}
+
+	cases := []Case{
+		{name: "Own unused upload", mediaID: upload.ID, wantCode: http.StatusOK},
+		{name: "Unknown upload", mediaID: uuid.New(), wantCode: http.StatusBadRequest},
+		{name: "Another user's upload", mediaID: other.ID, wantCode: http.StatusForbidden},
+		{name: "Avatar upload", mediaID: avatar.ID, wantCode: http.StatusBadRequest},
+		{name: "Already attached", mediaID: upload.ID, used: true, wantCode: http.StatusConflict},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			fake.on("GetMediaUploadsByIdsForUpdate", func(args []driver.Value) ([][]driver.Value, error) {
+				rows := [][]driver.Value{}
+				for _, stored := range []database.MediaUpload{upload, other, avatar} {
+					if slices.Contains(argUUIDs(args, 0), stored.ID) {
+						rows = append(rows, modelRow(stored))
+					}
+				}
+				return rows, nil
+			})
+			fake.on("GetUsedMediaIds", func(args []driver.Value) ([][]driver.Value, error) {
+				if !c.used {
+					return nil, nil
+				}
+				return [][]driver.Value{{argUUIDs(args, 0)[0].String()}}, nil
+			})
+
+			code, err := checkChirpMedia(context.Background(), cfg.database, ownerID, []ChirpMediaRequestBody{{ID: c.mediaID}})
+			if code != c.wantCode {
+				t.Errorf("checkChirpMedia() = %d, %v, want %d", code, err, c.wantCode)
+			}
+			if (err == nil) != (c.wantCode == http.StatusOK) {
+				t.Errorf("checkChirpMedia() error = %v", err)
+			}
+		})
+	}
+}
+
+type fakeStorage struct {
+	deleted []string
+}
+
+func (s *fakeStorage) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	return nil
+}
+
+func (s *fakeStorage) Delete(ctx context.Context, key string) error {
+	s.deleted = append(s.deleted, key)
+	return nil
+}
+
+func (s *fakeStorage) URL(key string) string { return "/media/" + key }
+
+func TestCollectOrphanedMedia(t *testing.T) {
+	type Case struct {
+		name        string
+		orphans     int
+		failDelete  bool
+		wantDeleted int
+		wantCommits int
+		wantErr     bool
+	}
+
+	cases := []Case{
+		{name: "Nothing to collect", orphans: 0, wantCommits: 1},
+		{name: "One batch", orphans: 3, wantDeleted: 3, wantCommits: 1},
+		{name: "Full batch is followed by another", orphans: mediaGCBatchSize + 1, wantDeleted: mediaGCBatchSize + 1, wantCommits: 2},
+		{name: "Failed delete keeps blobs", orphans: 3, failDelete: true, wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			blobs := &fakeStorage{}
+			cfg.storage = blobs
+
+			orphans := []database.MediaUpload{}
+			for i := 0; i < c.orphans; i++ {
+				id := uuid.New()
+				orphans = append(orphans, database.MediaUpload{ID: id, StorageKey: id.String(), ThumbnailKey: id.String() + "-thumb"})
+			}
+			fake.on("GetOrphanedMediaUploads", func(args []driver.Value) ([][]driver.Value, error) {
+				rows := [][]driver.Value{}
+				for _, orphan := range orphans {
+					if int64(len(rows)) == args[1].(int64) {
+						break
+					}
+					rows = append(rows, modelRow(orphan))
+				}
+				return rows, nil
+			})
+			fake.on("DeleteMediaUpload", func(args []driver.Value) ([][]driver.Value, error) {
+				if c.failDelete {
+					return nil, errors.New("connection reset")
+				}
+				orphans = slices.DeleteFunc(orphans, func(orphan database.MediaUpload) bool {
+					return orphan.ID == argUUID(args, 0)
+				})
+				return nil, nil
+			})
+
+			deleted, err := cfg.collectOrphanedMedia(context.Background(), time.Now().Add(-mediaOrphanAge))
+			if (err != nil) != c.wantErr {
+				t.Fatalf("collectOrphanedMedia() error = %v, want error %v", err, c.wantErr)
+			}
+			if deleted != c.wantDeleted {
+				t.Errorf("collectOrphanedMedia() = %d, want %d", deleted, c.wantDeleted)
+			}
+			if fake.commits != c.wantCommits {
+				t.Errorf("Committed %d times, want %d", fake.commits, c.wantCommits)
+			}
+			if len(blobs.deleted) != 2*c.wantDeleted {
+				t.Errorf("Deleted %d blobs, want %d", len(blobs.deleted), 2*c.wantDeleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func databaseChirpToResponse(chirp database.Chirp) ChirpResponseBody {
+	out := ChirpResponseBody{
+		ID:        chirp.ID,
+		CreatedAt: chirp.CreatedAt,
+		UpdatedAt: chirp.UpdatedAt,
+		Body:      chirp.Body,
+		UserID:    chirp.UserID,
+		Deleted:   chirp.DeletedAt.Valid,
+		Hidden:    chirp.HiddenAt.Valid,
+	}
+	if chirp.ParentID.Valid {
+		out.InReplyTo = &chirp.ParentID.UUID
+	}
+	if chirp.RootID.Valid {
+		out.RootID = &chirp.RootID.UUID
+	}
+
+	return out
+}
+
+func (cfg *apiConfig) chirpsToResponses(ctx context.Context, chirps []database.Chirp, viewerID uuid.UUID) ([]ChirpResponseBody, error) {
+	return cfg.buildChirpResponses(ctx, chirps, viewerID, true)
+}
+
+func (cfg *apiConfig) chirpToResponse(ctx context.Context, chirp database.Chirp, viewerID uuid.UUID) (ChirpResponseBody, error) {
+	responses, err := cfg.chirpsToResponses(ctx, []database.Chirp{chirp}, viewerID)
+	if err != nil {
+		return ChirpResponseBody{}, err
+	}
+
+	return responses[0], nil
+}
+
+// Quoted and rechirped chirps are embedded one level deep; their own
+// references are left as IDs so a quote chain cannot fan out.
+func (cfg *apiConfig) buildChirpResponses(ctx context.Context, chirps []database.Chirp, viewerID uuid.UUID, embedReferences bool) ([]ChirpResponseBody, error) {
+	out := make([]ChirpResponseBody, 0, len(chirps))
+	if len(chirps) == 0 {
+		return out, nil
+	}
+
+	chirpIDs := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		chirpIDs = append(chirpIDs, chirp.ID)
+	}
+
+	likeCounts, err := cfg.database.GetChirpLikeCounts(ctx, chirpIDs)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting like counts: %w", err)
+	}
+	likeCountByChirp := make(map[uuid.UUID]int64, len(likeCounts))
+	for _, likeCount := range likeCounts {
+		likeCountByChirp[likeCount.ChirpID] = likeCount.LikeCount
+	}
+
+	rechirpCounts, err := cfg.database.GetRechirpCounts(ctx, chirpIDs)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting rechirp counts: %w", err)
+	}
+	rechirpCountByChirp := make(map[uuid.UUID]int64, len(rechirpCounts))
+	for _, rechirpCount := range rechirpCounts {
+		rechirpCountByChirp[rechirpCount.ChirpID] = rechirpCount.RechirpCount
+	}
+
+	quoteCounts, err := cfg.database.GetQuoteCounts(ctx, chirpIDs)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting quote counts: %w", err)
+	}
+	quoteCountByChirp := make(map[uuid.UUID]int64, len(quoteCounts))
+	for _, quoteCount := range quoteCounts {
+		quoteCountByChirp[quoteCount.ChirpID] = quoteCount.QuoteCount
+	}
+
+	likedByViewer := map[uuid.UUID]bool{}
+	bookmarkedByViewer := map[uuid.UUID]bool{}
+	if viewerID != uuid.Nil {
+		likedChirpIDs, err := cfg.database.GetChirpIdsLikedByUser(ctx, database.GetChirpIdsLikedByUserParams{
+			UserID:   viewerID,
+			ChirpIds: chirpIDs,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Error getting liked chirps: %w", err)
+		}
+		for _, chirpID := range likedChirpIDs {
+			likedByViewer[chirpID] = true
+		}
+
+		bookmarkedChirpIDs, err := cfg.database.GetChirpIdsBookmarkedByUser(ctx, database.GetChirpIdsBookmarkedByUserParams{
+			UserID:   viewerID,
+			ChirpIds: chirpIDs,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Error getting bookmarked chirps: %w", err)
+		}
+		for _, chirpID := range bookmarkedChirpIDs {
+			bookmarkedByViewer[chirpID] = true
+		}
+	}
+
+	mentions, err := cfg.database.GetChirpMentions(ctx, chirpIDs)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting chirp mentions: %w", err)
+	}
+	mentionsByChirp := make(map[uuid.UUID][]MentionResponseBody, len(chirps))
+	for _, mention := range mentions {
+		mentionsByChirp[mention.ChirpID] = append(mentionsByChirp[mention.ChirpID], MentionResponseBody{
+			UserID: mention.UserID,
+			Handle: mention.Handle.String,
+			Start:  mention.StartOffset,
+			End:    mention.EndOffset,
+		})
+	}
+
+	mediaByChirp, err := cfg.chirpMediaByChirp(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	pollByChirp, err := cfg.chirpPollsByChirp(ctx, chirpIDs, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	authorIDs := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		authorIDs = append(authorIDs, chirp.UserID)
+	}
+	authors, err := cfg.database.GetUsersByIds(ctx, authorIDs)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting chirp authors: %w", err)
+	}
+	now := time.Now().UTC()
+	authorByID := make(map[uuid.UUID]*ChirpAuthorResponseBody, len(authors))
+	restrictedAuthors := map[uuid.UUID]bool{}
+	for _, author := range authors {
+		if isAccountRestricted(author.AccountStatus, author.StatusExpiresAt, now) {
+			restrictedAuthors[author.ID] = true
+		}
+		authorByID[author.ID] = &ChirpAuthorResponseBody{
+			ID:          author.ID,
+			Handle:      author.Handle.String,
+			DisplayName: author.DisplayName,
+			AvatarURL:   author.AvatarUrl,
+			IsChirpyRed: author.IsChirpyRed,
+		}
+	}
+
+	referenced := map[uuid.UUID]*ChirpResponseBody{}
+	if embedReferences {
+		referencedIDs := []uuid.UUID{}
+		for _, chirp := range chirps {
+			if chirp.QuoteOf.Valid {
+				referencedIDs = append(referencedIDs, chirp.QuoteOf.UUID)
+			}
+			if chirp.RechirpOf.Valid {
+				referencedIDs = append(referencedIDs, chirp.RechirpOf.UUID)
+			}
+		}
+
+		if len(referencedIDs) > 0 {
+			referencedChirps, err := cfg.database.GetChirpsByIds(ctx, referencedIDs)
+			if err != nil {
+				return nil, fmt.Errorf("Error getting referenced chirps: %w", err)
+			}
+
+			referencedResponses, err := cfg.buildChirpResponses(ctx, referencedChirps, viewerID, false)
+			if err != nil {
+				return nil, err
+			}
+			for i := range referencedResponses {
+				referenced[referencedResponses[i].ID] = &referencedResponses[i]
+			}
+		}
+	}
+
+	for _, chirp := range chirps {
+		response := databaseChirpToResponse(chirp)
+		response.LikeCount = likeCountByChirp[chirp.ID]
+		response.LikedByMe = likedByViewer[chirp.ID]
+		response.BookmarkedByMe = bookmarkedByViewer[chirp.ID]
+		response.RechirpCount = rechirpCountByChirp[chirp.ID]
+		response.QuoteCount = quoteCountByChirp[chirp.ID]
+		response.Mentions = mentionsByChirp[chirp.ID]
+		response.Media = mediaByChirp[chirp.ID]
+		response.Poll = pollByChirp[chirp.ID]
+		response.Author = authorByID[chirp.UserID]
+		if response.Mentions == nil {
+			response.Mentions = []MentionResponseBody{}
+		}
+		if response.Media == nil {
+			response.Media = []ChirpMediaResponseBody{}
+		}
+		if chirp.QuoteOf.Valid {
+			response.QuoteOf = referenced[chirp.QuoteOf.UUID]
+		}
+		if chirp.RechirpOf.Valid {
+			response.RechirpOf = referenced[chirp.RechirpOf.UUID]
+		}
+		// Listings leave out suspended and banned authors' chirps, but
+		// threads and embeds still reach them, so they are hidden here.
+		if restrictedAuthors[chirp.UserID] {
+			response.Hidden = true
+		}
+		// Deleted chirps keep their content for restoring, and hidden ones
+		// for appeals, but only their author may still see it.
+		if (response.Deleted || response.Hidden) && chirp.UserID != viewerID {
+			response.Body = ""
+			response.Mentions = []MentionResponseBody{}
+			response.Media = []ChirpMediaResponseBody{}
+			response.Poll = nil
+		}
+		out = append(out, response)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestChirpEmbedDepth(t *testing.T) {
+	authorID := uuid.New()
+	now := time.Now().UTC()
+	chirp := func(body string) database.Chirp {
+		return database.Chirp{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Body: body, UserID: authorID}
+	}
+	quote := func(body string, of database.Chirp) database.Chirp {
+		c := chirp(body)
+		c.QuoteOf = uuid.NullUUID{UUID: of.ID, Valid: true}
+		return c
+	}
+
+	original := chirp("original")
+	quoted := quote("quote", original)
+	quoteOfQuote := quote("quote of a quote", quoted)
+	rechirp := chirp("")
+	rechirp.RechirpOf = uuid.NullUUID{UUID: quoted.ID, Valid: true}
+	deleted := chirp("gone")
+	deleted.DeletedAt = sql.NullTime{Time: now, Valid: true}
+	quoteOfDeleted := quote("quoting something gone", deleted)
+
+	type Case struct {
+		name         string
+		chirp        database.Chirp
+		wantQuote    *database.Chirp
+		wantRechirp  *database.Chirp
+		wantEmbedded string
+	}
+
+	cases := []Case{
+		{
+			name:  "Plain chirp",
+			chirp: original,
+		},
+		{
+			name:         "Quote embeds the original",
+			chirp:        quoted,
+			wantQuote:    &original,
+			wantEmbedded: "original",
+		},
+		{
+			name:         "Quote of a quote stops one level down",
+			chirp:        quoteOfQuote,
+			wantQuote:    &quoted,
+			wantEmbedded: "quote",
+		},
+		{
+			name:         "Rechirp of a quote stops one level down",
+			chirp:        rechirp,
+			wantRechirp:  &quoted,
+			wantEmbedded: "quote",
+		},
+		{
+			name:         "Quote of a deleted chirp embeds a tombstone",
+			chirp:        quoteOfDeleted,
+			wantQuote:    &deleted,
+			wantEmbedded: "",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, fake := newTestConfig(t)
+			fake.stubChirpResponses()
+			fakeChirps(fake, original, quoted, quoteOfQuote, rechirp, deleted, quoteOfDeleted)
+
+			got, err := cfg.chirpToResponse(context.Background(), c.chirp, uuid.Nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			embedded := got.QuoteOf
+			want := c.wantQuote
+			if c.wantRechirp != nil {
+				embedded, want = got.RechirpOf, c.wantRechirp
+			}
+			if want == nil {
+				if got.QuoteOf != nil || got.RechirpOf != nil {
+					t.Errorf("Embedded %+v %+v, want nothing", got.QuoteOf, got.RechirpOf)
+				}
+				return
+			}
+			if embedded == nil || embedded.ID != want.ID {
+				t.Fatalf("Embedded %+v, want %v", embedded, want.ID)
+			}
+			if embedded.Body != c.wantEmbedded {
+				t.Errorf("Embedded body = %q, want %q", embedded.Body, c.wantEmbedded)
+			}
+			if embedded.QuoteOf != nil || embedded.RechirpOf != nil {
+				t.Errorf("Embedded chirp embeds another, want one level only")
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/auth"
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/delroscol98/chirpy/internal/moderation"
+	"github.com/delroscol98/chirpy/internal/stream"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const testSecret = "test-secret"
+
+// fakeDB stands in for Postgres in handler tests. Queries are routed by
+// their sqlc name to the stubs a test registers with on; any other query
+// fails, so a test notices when a handler touches something unexpected.
+type fakeDB struct {
+	mu      sync.Mutex
+	queries map[string]fakeQuery
+	calls   []string
+	commits int
+}
+
+// fakeQuery answers one query. args are the driver values the generated
+// code passed, so UUIDs arrive as strings.
+type fakeQuery func(args []driver.Value) ([][]driver.Value, error)
+
+var queryName = regexp.MustCompile(`-- name: (\w+)`)
+
+// newTestConfig returns a config wired to a fresh fakeDB, with the checks
+// every authenticated request makes already answered: all accounts are
+// active and nobody is blocked or muted.
+func newTestConfig(t *testing.T) (*apiConfig, *fakeDB) {
+	t.Helper()
+
+	fake := &fakeDB{queries: map[string]fakeQuery{}}
+	db := sql.OpenDB(fakeConnector{fake})
+	t.Cleanup(func() { db.Close() })
+
+	fake.on("GetUserAccountStatus", func(args []driver.Value) ([][]driver.Value, error) {
+		return [][]driver.Value{{accountStatusActive, "", nil}}, nil
+	})
+	fake.onRows("HasBlockBetween", []driver.Value{false})
+	fake.onRows("HasBlockAmong", []driver.Value{false})
+	fake.on("GetHiddenAuthorIds", noRows)
+
+	filter, err := moderation.NewLive(context.Background(), moderation.SourceFunc(func(context.Context) ([]moderation.Rule, error) {
+		return nil, nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &apiConfig{
+		db:              db,
+		database:        database.New(db),
+		secret:          testSecret,
+		chirpEditWindow: 15 * time.Minute,
+		trashRetention:  30 * 24 * time.Hour,
+		chirpStream:     stream.NewMemory(100, 64),
+		userStream:      stream.NewMemory(0, 64),
+		moderation:      filter,
+	}
+
+	return cfg, fake
+}
+
+// stubChirpResponses answers the lookups that building chirp responses
+// and indexing a chirp body make, as if nothing was linked to the chirps.
+func (f *fakeDB) stubChirpResponses() {
+	for _, name := range []string{
+		"GetChirpLikeCounts",
+		"GetRechirpCounts",
+		"GetQuoteCounts",
+		"GetChirpIdsLikedByUser",
+		"GetChirpIdsBookmarkedByUser",
+		"GetChirpMentions",
+		"GetChirpMedia",
+		"GetPollsByChirpIds",
+		"GetUsersByIds",
+		"GetChirpsByIds",
+		"GetChirpMentionedUserIds",
+	} {
+		f.on(name, noRows)
+	}
+	f.on("DeleteChirpHashtags", execOK(0))
+	f.on("DeleteChirpMentions", execOK(0))
+}
+
+// fakeChirps answers GetChirpById and GetChirpsByIds from a fixed set.
+func fakeChirps(fake *fakeDB, chirps ...database.Chirp) {
+	byID := map[uuid.UUID]database.Chirp{}
+	for _, chirp := range chirps {
+		byID[chirp.ID] = chirp
+	}
+
+	fake.on("GetChirpById", func(args []driver.Value) ([][]driver.Value, error) {
+		chirp, ok := byID[argUUID(args, 0)]
+		if !ok {
+			return nil, nil
+		}
+		return [][]driver.Value{modelRow(chirp)}, nil
+	})
+	fake.on("GetChirpsByIds", func(args []driver.Value) ([][]driver.Value, error) {
+		rows := [][]driver.Value{}
+		for _, id := range argUUIDs(args, 0) {
+			if chirp, ok := byID[id]; ok {
+				rows = append(rows, modelRow(chirp))
+			}
+		}
+		return rows, nil
+	})
+}
+
+func (f *fakeDB) on(name string, query fakeQuery) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.queries[name] = query
+}
+
+// onRows answers name with fixed rows, each built by modelRow or listed
+// column by column.
+func (f *fakeDB) onRows(name string, rows ...[]driver.Value) {
+	f.on(name, func(args []driver.Value) ([][]driver.Value, error) {
+		return rows, nil
+	})
+}
+
+// called reports how many times the named query ran.
+func (f *fakeDB) called(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := 0
+	for _, call := range f.calls {
+		if call == name {
+			n++
+		}
+	}
+	return n
+}
+
+func (f *fakeDB) run(query string, named []driver.NamedValue) ([][]driver.Value, error) {
+	match := queryName.FindStringSubmatch(query)
+	if match == nil {
+		return nil, fmt.Errorf("fakeDB: query has no name: %s", query)
+	}
+
+	f.mu.Lock()
+	f.calls = append(f.calls, match[1])
+	handler, ok := f.queries[match[1]]
+	f.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("fakeDB: unexpected query %s", match[1])
+	}
+
+	args := make([]driver.Value, len(named))
+	for i, arg := range named {
+		args[i] = arg.Value
+	}
+	return handler(args)
+}
+
+func noRows(args []driver.Value) ([][]driver.Value, error) {
+	return nil, nil
+}
+
+// execOK answers an :exec or :execrows query as affecting n rows.
+func execOK(n int) fakeQuery {
+	return func(args []driver.Value) ([][]driver.Value, error) {
+		return make([][]driver.Value, n), nil
+	}
+}
+
+// modelRow flattens a sqlc model into the column values a SELECT * for it
+// returns, in field order.
+func modelRow(model any) []driver.Value {
+	v := reflect.ValueOf(model)
+	row := make([]driver.Value, 0, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i).Interface()
+		if v.Field(i).Kind() == reflect.Slice {
+			field = pq.Array(field)
+		}
+		value, err := driver.DefaultParameterConverter.ConvertValue(field)
+		if err != nil {
+			panic(fmt.Sprintf("modelRow: field %s: %v", v.Type().Field(i).Name, err))
+		}
+		row = append(row, value)
+	}
+	return row
+}
+
+func argUUID(args []driver.Value, i int) uuid.UUID {
+	id, _ := uuid.Parse(fmt.Sprint(args[i]))
+	return id
+}
+
+// argUUIDs decodes a uuid[] argument, which arrives in Postgres array
+// syntax.
+func argUUIDs(args []driver.Value, i int) []uuid.UUID {
+	ids := []uuid.UUID{}
+	list := strings.Trim(fmt.Sprint(args[i]), "{}")
+	if list == "" {
+		return ids
+	}
+	for _, raw := range strings.Split(list, ",") {
+		id, _ := uuid.Parse(strings.Trim(raw, `"`))
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// authHeader returns a bearer header carrying a fresh access token.
+func authHeader(t *testing.T, userID uuid.UUID) http.Header {
+	t.Helper()
+
+	token, err := auth.MakeJWT(userID, testSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return http.Header{"Authorization": []string{"Bearer " + token}}
+}
+
+type fakeConnector struct{ db *fakeDB }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{db: c.db}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, fmt.Errorf("fakeDB: open through the connector")
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{c.db}, nil }
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.db.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{rows: rows}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	rows, err := c.db.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(len(rows)), nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+type fakeTx struct{ db *fakeDB }
+
+func (tx fakeTx) Commit() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+
+	tx.db.commits++
+	return nil
+}
+
+func (tx fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
@@ -1,24 +1,257 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/delroscol98/chirpy/internal/auth"
+	"github.com/delroscol98/chirpy/internal/entities"
+	"github.com/delroscol98/chirpy/internal/pagination"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const maxChirpLength = 140
+
+func (cfg *apiConfig) validateChirpBody(body string) (string, error) {
+	if len(body) > maxChirpLength {
+		return "", errors.New("chirps must be at most 140 characters long")
+	}
+
+	return cfg.moderation.Clean(body), nil
+}
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 4
+	maxPollOptionLength = 25
+	minPollDuration     = 5 * time.Minute
+	maxPollDuration     = 7 * 24 * time.Hour
+)
+
+// validatePoll returns the poll with its option labels trimmed.
+func validatePoll(poll PollRequestBody, now time.Time) (PollRequestBody, error) {
+	if len(poll.Options) < minPollOptions || len(poll.Options) > maxPollOptions {
+		return PollRequestBody{}, fmt.Errorf("Polls must have %d-%d options", minPollOptions, maxPollOptions)
+	}
+
+	options := make([]string, 0, len(poll.Options))
+	seen := make(map[string]bool, len(poll.Options))
+	for _, option := range poll.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return PollRequestBody{}, errors.New("Poll options must not be empty")
+		}
+		if utf8.RuneCountInString(option) > maxPollOptionLength {
+			return PollRequestBody{}, fmt.Errorf("Poll options must be at most %d characters long", maxPollOptionLength)
+		}
+
+		folded := strings.ToLower(option)
+		if seen[folded] {
+			return PollRequestBody{}, errors.New("Poll options must not be repeated")
+		}
+		seen[folded] = true
+		options = append(options, option)
+	}
+
+	duration := poll.ClosesAt.Sub(now)
+	if duration < minPollDuration || duration > maxPollDuration {
+		return PollRequestBody{}, fmt.Errorf("Polls must close between %v and %v from now", minPollDuration, maxPollDuration)
+	}
+
+	return PollRequestBody{Options: options, ClosesAt: poll.ClosesAt.UTC()}, nil
+}
+
+// Drafts may be work in progress, so they are only held to the chirp rules
+// when published. This cap just keeps them from being used as storage.
+const maxDraftLength = 10000
+
+func validateDraftBody(body string) error {
+	if len(body) > maxDraftLength {
+		return fmt.Errorf("Drafts must be at most %d characters long", maxDraftLength)
+	}
+
+	return nil
+}
+
+const maxReportDetailsLength = 1000
+
+var reportReasons = map[string]bool{
+	"spam":           true,
+	"harassment":     true,
+	"hate":           true,
+	"violence":       true,
+	"sexual":         true,
+	"misinformation": true,
+	"other":          true,
+}
+
+func validateReport(params ReportRequestBody) (ReportRequestBody, error) {
+	if !reportReasons[params.Reason] {
+		return ReportRequestBody{}, fmt.Errorf("Unknown report reason %q", params.Reason)
+	}
+	params.Details = strings.TrimSpace(params.Details)
+	if utf8.RuneCountInString(params.Details) > maxReportDetailsLength {
+		return ReportRequestBody{}, fmt.Errorf("Report details must be at most %d characters long", maxReportDetailsLength)
+	}
+
+	return params, nil
+}
+
+const (
+	maxChirpMedia     = 4
+	maxMediaAltLength = 1000
+)
+
+func validateChirpMedia(attachments []ChirpMediaRequestBody) error {
+	if len(attachments) > maxChirpMedia {
+		return fmt.Errorf("Chirps can have at most %d attachments", maxChirpMedia)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(attachments))
+	for _, attachment := range attachments {
+		if seen[attachment.ID] {
+			return errors.New("Attachments must not be repeated")
+		}
+		seen[attachment.ID] = true
+
+		if utf8.RuneCountInString(attachment.AltText) > maxMediaAltLength {
+			return fmt.Errorf("Alt text must be at most %d characters long", maxMediaAltLength)
+		}
+	}
+
+	return nil
+}
+
+const (
+	maxDisplayNameLength = 50
+	maxBioLength         = 160
+	maxAvatarURLLength   = 2048
 )
 
-func cleanBody(body string) string {
-	bodyWords := strings.Split(body, " ")
-	profraneWords := []string{"kerfuffle", "sharbert", "fornax"}
+func validateHandle(handle string) error {
+	if !entities.ValidHandle(handle) {
+		return fmt.Errorf("Handles must be 1-%d letters, digits or underscores", entities.MaxHandleLength)
+	}
+	if entities.IsReservedHandle(handle) {
+		return fmt.Errorf("Handle %q is reserved", handle)
+	}
+
+	return nil
+}
+
+func validateProfile(displayName, bio, avatarURL string) error {
+	if utf8.RuneCountInString(displayName) > maxDisplayNameLength {
+		return fmt.Errorf("Display names must be at most %d characters long", maxDisplayNameLength)
+	}
+	if utf8.RuneCountInString(bio) > maxBioLength {
+		return fmt.Errorf("Bios must be at most %d characters long", maxBioLength)
+	}
+	if avatarURL != "" {
+		parsed, err := url.Parse(avatarURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return errors.New("Avatar URL must be an absolute http or https URL")
+		}
+		if len(avatarURL) > maxAvatarURLLength {
+			return fmt.Errorf("Avatar URLs must be at most %d characters long", maxAvatarURLLength)
+		}
+	}
+
+	return nil
+}
+
+const maxDirectMessageLength = 1000
+
+func validateDirectMessageBody(body string) (string, error) {
+	if strings.TrimSpace(body) == "" {
+		return "", errors.New("Messages must not be empty")
+	}
+	if len(body) > maxDirectMessageLength {
+		return "", fmt.Errorf("Messages must be at most %d characters long", maxDirectMessageLength)
+	}
+
+	return body, nil
+}
+
+type pageParams struct {
+	limit           int32
+	cursorCreatedAt sql.NullTime
+	cursorID        uuid.NullUUID
+}
+
+func parsePageParams(r *http.Request) (pageParams, error) {
+	query := r.URL.Query()
+
+	limit, err := pagination.ParseLimit(query.Get("limit"))
+	if err != nil {
+		return pageParams{}, err
+	}
 
-	for _, profaneWord := range profraneWords {
-		for index, bodyWord := range bodyWords {
-			if profaneWord == strings.ToLower(bodyWord) {
-				bodyWords[index] = "****"
-			}
+	params := pageParams{limit: limit}
+	if rawCursor := query.Get("cursor"); rawCursor != "" {
+		cursor, err := pagination.DecodeCursor(rawCursor)
+		if err != nil {
+			return pageParams{}, fmt.Errorf("Error parsing cursor: %w", err)
 		}
+		params.cursorCreatedAt = sql.NullTime{Time: cursor.CreatedAt, Valid: true}
+		params.cursorID = uuid.NullUUID{UUID: cursor.ID, Valid: true}
+	}
+
+	return params, nil
+}
+
+func nextPageCursor(createdAt time.Time, id uuid.UUID) string {
+	return pagination.EncodeCursor(pagination.Cursor{
+		CreatedAt: createdAt,
+		ID:        id,
+	})
+}
+
+func (cfg *apiConfig) viewerID(r *http.Request) uuid.UUID {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return uuid.Nil
+	}
+
+	userID, err := cfg.validateAccessToken(r.Context(), accessToken)
+	if err != nil {
+		return uuid.Nil
 	}
 
-	return strings.Join(bodyWords, " ")
+	return userID
+}
+
+func optionalUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
+
+func uuidPointer(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+
+	return &id.UUID
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
 }
 
 func respondWithJSON(w http.ResponseWriter, code int, payload any) error {
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestValidateReport(t *testing.T) {
+	type Case struct {
+		name    string
+		params  ReportRequestBody
+		want    ReportRequestBody
+		wantErr string
+	}
+
+	cases := []Case{
+		{
+			name:   "Reason without details",
+			params: ReportRequestBody{Reason: "spam"},
+			want:   ReportRequestBody{Reason: "spam"},
+		},
+		{
+			name:   "Details are trimmed",
+			params: ReportRequestBody{Reason: "other", Details: "  see the link  "},
+			want:   ReportRequestBody{Reason: "other", Details: "see the link"},
+		},
+		{
+			name:   "Details at the limit count characters",
+			params: ReportRequestBody{Reason: "hate", Details: strings.Repeat("é", maxReportDetailsLength)},
+			want:   ReportRequestBody{Reason: "hate", Details: strings.Repeat("é", maxReportDetailsLength)},
+		},
+		{
+			name:    "Unknown reason",
+			params:  ReportRequestBody{Reason: "boring"},
+			wantErr: `Unknown report reason "boring"`,
+		},
+		{
+			name:    "Reasons are case sensitive",
+			params:  ReportRequestBody{Reason: "Spam"},
+			wantErr: `Unknown report reason "Spam"`,
+		},
+		{
+			name:    "Details too long",
+			params:  ReportRequestBody{Reason: "spam", Details: strings.Repeat("a", maxReportDetailsLength+1)},
+			wantErr: "Report details must be at most 1000 characters long",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := validateReport(c.params)
+			if c.wantErr != "" {
+				if err == nil || err.Error() != c.wantErr {
+					t.Errorf("validateReport() error = %v, want %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateReport() error = %v", err)
+			}
+			if got != c.want {
+				t.Errorf("validateReport() = %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestValidateDirectMessageBody(t *testing.T) {
+	type Case struct {
+		name    string
+		body    string
+		wantErr string
+	}
+
+	cases := []Case{
+		{
+			name: "Message",
+			body: "hello",
+		},
+		{
+			name: "At the limit",
+			body: strings.Repeat("a", maxDirectMessageLength),
+		},
+		{
+			name:    "Blank",
+			body:    " \n\t",
+			wantErr: "Messages must not be empty",
+		},
+		{
+			name:    "Too long",
+			body:    strings.Repeat("a", maxDirectMessageLength+1),
+			wantErr: "Messages must be at most 1000 characters long",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := validateDirectMessageBody(c.body)
+			if c.wantErr != "" {
+				if err == nil || err.Error() != c.wantErr {
+					t.Errorf("validateDirectMessageBody() error = %v, want %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateDirectMessageBody() error = %v", err)
+			}
+			if got != c.body {
+				t.Errorf("validateDirectMessageBody() = %q, want %q", got, c.body)
+			}
+		})
+	}
+}
+
+func TestValidateChirpMedia(t *testing.T) {
+	first := uuid.New()
+
+	type Case struct {
+		name        string
+		attachments []ChirpMediaRequestBody
+		wantErr     string
+	}
+
+	cases := []Case{
+		{
+			name: "No attachments",
+		},
+		{
+			name:        "At the limit",
+			attachments: []ChirpMediaRequestBody{{ID: first}, {ID: uuid.New()}, {ID: uuid.New()}, {ID: uuid.New(), AltText: strings.Repeat("é", maxMediaAltLength)}},
+		},
+		{
+			name:        "Too many",
+			attachments: []ChirpMediaRequestBody{{ID: first}, {ID: uuid.New()}, {ID: uuid.New()}, {ID: uuid.New()}, {ID: uuid.New()}},
+			wantErr:     "Chirps can have at most 4 attachments",
+		},
+		{
+			name:        "Repeated",
+			attachments: []ChirpMediaRequestBody{{ID: first}, {ID: first}},
+			wantErr:     "Attachments must not be repeated",
+		},
+		{
+			name:        "Alt text too long",
+			attachments: []ChirpMediaRequestBody{{ID: first, AltText: strings.Repeat("a", maxMediaAltLength+1)}},
+			wantErr:     "Alt text must be at most 1000 characters long",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateChirpMedia(c.attachments)
+			if c.wantErr != "" {
+				if err == nil || err.Error() != c.wantErr {
+					t.Errorf("validateChirpMedia() error = %v, want %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("validateChirpMedia() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestValidatePoll(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	inAnHour := now.Add(time.Hour)
+
+	type Case struct {
+		name    string
+		poll    PollRequestBody
+		want    PollRequestBody
+		wantErr string
+	}
+
+	cases := []Case{
+		{
+			name: "Options are trimmed",
+			poll: PollRequestBody{Options: []string{" yes ", "no"}, ClosesAt: inAnHour},
+			want: PollRequestBody{Options: []string{"yes", "no"}, ClosesAt: inAnHour},
+		},
+		{
+			name: "Closing time is stored in UTC",
+			poll: PollRequestBody{Options: []string{"yes", "no"}, ClosesAt: inAnHour.In(time.FixedZone("AEST", 10*60*60))},
+			want: PollRequestBody{Options: []string{"yes", "no"}, ClosesAt: inAnHour},
+		},
+		{
+			name: "Limits",
+			poll: PollRequestBody{Options: []string{"a", "b", "c", strings.Repeat("é", maxPollOptionLength)}, ClosesAt: now.Add(maxPollDuration)},
+			want: PollRequestBody{Options: []string{"a", "b", "c", strings.Repeat("é", maxPollOptionLength)}, ClosesAt: now.Add(maxPollDuration)},
+		},
+		{
+			name:    "Too few options",
+			poll:    PollRequestBody{Options: []string{"yes"}, ClosesAt: inAnHour},
+			wantErr: "Polls must have 2-4 options",
+		},
+		{
+			name:    "Too many options",
+			poll:    PollRequestBody{Options: []string{"a", "b", "c", "d", "e"}, ClosesAt: inAnHour},
+			wantErr: "Polls must have 2-4 options",
+		},
+		{
+			name:    "Blank option",
+			poll:    PollRequestBody{Options: []string{"yes", "  "}, ClosesAt: inAnHour},
+			wantErr: "Poll options must not be empty",
+		},
+		{
+			name:    "Option too long",
+			poll:    PollRequestBody{Options: []string{"yes", strings.Repeat("a", maxPollOptionLength+1)}, ClosesAt: inAnHour},
+			wantErr: "Poll options must be at most 25 characters long",
+		},
+		{
+			name:    "Options differing only in case",
+			poll:    PollRequestBody{Options: []string{"Yes", "yes "}, ClosesAt: inAnHour},
+			wantErr: "Poll options must not be repeated",
+		},
+		{
+			name:    "Closes too soon",
+			poll:    PollRequestBody{Options: []string{"yes", "no"}, ClosesAt: now.Add(minPollDuration - time.Second)},
+			wantErr: "Polls must close between 5m0s and 168h0m0s from now",
+		},
+		{
+			name:    "Already closed",
+			poll:    PollRequestBody{Options: []string{"yes", "no"}, ClosesAt: now.Add(-time.Hour)},
+			wantErr: "Polls must close between 5m0s and 168h0m0s from now",
+		},
+		{
+			name:    "Closes too late",
+			poll:    PollRequestBody{Options: []string{"yes", "no"}, ClosesAt: now.Add(maxPollDuration + time.Second)},
+			wantErr: "Polls must close between 5m0s and 168h0m0s from now",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := validatePoll(c.poll, now)
+			if c.wantErr != "" {
+				if err == nil || err.Error() != c.wantErr {
+					t.Errorf("validatePoll() error = %v, want %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validatePoll() error = %v", err)
+			}
+			if !slices.Equal(got.Options, c.want.Options) || got.ClosesAt != c.want.ClosesAt {
+				t.Errorf("validatePoll() = %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
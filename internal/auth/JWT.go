@@ -29,6 +29,14 @@ func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (str
 }
 
 func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
+	id, _, err := ValidateJWTWithExpiry(tokenString, tokenSecret)
+	return id, err
+}
+
+// ValidateJWTWithExpiry validates the token like ValidateJWT and also
+// returns when it expires, for long-lived connections that must drop
+// once their token is no longer valid.
+func ValidateJWTWithExpiry(tokenString, tokenSecret string) (uuid.UUID, time.Time, error) {
 	claimsStruct := jwt.RegisteredClaims{}
 	token, err := jwt.ParseWithClaims(
 		tokenString,
@@ -36,29 +44,34 @@ func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
 		func(token *jwt.Token) (any, error) { return []byte(tokenSecret), nil },
 	)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("Error parsing with claims: %w", err)
+		return uuid.Nil, time.Time{}, fmt.Errorf("Error parsing with claims: %w", err)
 	}
 
 	userIDString, err := token.Claims.GetSubject()
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("Error getting token subject: %w", err)
+		return uuid.Nil, time.Time{}, fmt.Errorf("Error getting token subject: %w", err)
 	}
 
 	issuer, err := token.Claims.GetIssuer()
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("Error getting token issuer: %w", err)
+		return uuid.Nil, time.Time{}, fmt.Errorf("Error getting token issuer: %w", err)
 	}
 
 	if issuer != "chirpy" {
-		return uuid.Nil, errors.New("invalid issuer")
+		return uuid.Nil, time.Time{}, errors.New("invalid issuer")
 	}
 
 	id, err := uuid.Parse(userIDString)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("Error converting uuid string to uuid: %w", err)
+		return uuid.Nil, time.Time{}, fmt.Errorf("Error converting uuid string to uuid: %w", err)
+	}
+
+	expiresAt, err := token.Claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return uuid.Nil, time.Time{}, errors.New("missing expiration time")
 	}
 
-	return id, nil
+	return id, expiresAt.Time, nil
 }
 
 func GetBearerToken(headers http.Header) (string, error) {
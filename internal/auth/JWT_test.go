@@ -61,6 +61,26 @@ func TestJWT(t *testing.T) {
 	}
 }
 
+func TestValidateJWTWithExpiry(t *testing.T) {
+	userID := uuid.New()
+	before := time.Now().Add(time.Hour).Add(-time.Second)
+	token, err := MakeJWT(userID, "secret", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotUserID, expiresAt, err := ValidateJWTWithExpiry(token, "secret")
+	if err != nil {
+		t.Fatalf("ValidateJWTWithExpiry() error = %v", err)
+	}
+	if gotUserID != userID {
+		t.Errorf("ValidateJWTWithExpiry() gotUserID = %v, want %v", gotUserID, userID)
+	}
+	if expiresAt.Before(before) || expiresAt.After(time.Now().Add(time.Hour)) {
+		t.Errorf("ValidateJWTWithExpiry() expiresAt = %v, want about an hour from now", expiresAt)
+	}
+}
+
 func TestGetBearerToken(t *testing.T) {
 	type Case struct {
 		name               string
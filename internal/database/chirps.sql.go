@@ -7,33 +7,120 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const countChirpQuotes = `-- name: CountChirpQuotes :one
+SELECT COUNT(*) FROM chirps
+WHERE chirps.quote_of = $1::uuid
+`
+
+func (q *Queries) CountChirpQuotes(ctx context.Context, chirpID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countChirpQuotes, chirpID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countChirpReplies = `-- name: CountChirpReplies :one
+SELECT COUNT(*) FROM chirps
+WHERE chirps.parent_id = $1::uuid
+`
+
+func (q *Queries) CountChirpReplies(ctx context.Context, chirpID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countChirpReplies, chirpID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createChirp = `-- name: CreateChirp :one
 INSERT INTO chirps(
   id,
   created_at,
   updated_at,
   body,
-  user_id
+  user_id,
+  parent_id,
+  root_id,
+  quote_of
 ) VALUES (
   gen_random_uuid(),
   NOW(),
   NOW(),
   $1,
-  $2
-) RETURNING id, created_at, updated_at, body, user_id
+  $2,
+  $3,
+  $4,
+  $5
+) RETURNING id, created_at, updated_at, body, user_id, parent_id, root_id, deleted_at, quote_of, rechirp_of, search_vector, purged_at, hidden_at
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.UUID
+	Body     string
+	UserID   uuid.UUID
+	ParentID uuid.NullUUID
+	RootID   uuid.NullUUID
+	QuoteOf  uuid.NullUUID
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp,
+		arg.Body,
+		arg.UserID,
+		arg.ParentID,
+		arg.RootID,
+		arg.QuoteOf,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.RootID,
+		&i.DeletedAt,
+		&i.QuoteOf,
+		&i.RechirpOf,
+		&i.SearchVector,
+		&i.PurgedAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const createRechirp = `-- name: CreateRechirp :one
+INSERT INTO chirps(
+  id,
+  created_at,
+  updated_at,
+  body,
+  user_id,
+  rechirp_of
+) VALUES (
+  gen_random_uuid(),
+  NOW(),
+  NOW(),
+  '',
+  $1,
+  $2
+) ON CONFLICT (user_id, rechirp_of) WHERE rechirp_of IS NOT NULL DO NOTHING
+RETURNING id, created_at, updated_at, body, user_id, parent_id, root_id, deleted_at, quote_of, rechirp_of, search_vector, purged_at, hidden_at
+`
+
+type CreateRechirpParams struct {
+	UserID    uuid.UUID
+	RechirpOf uuid.NullUUID
+}
+
+func (q *Queries) CreateRechirp(ctx context.Context, arg CreateRechirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, createRechirp, arg.UserID, arg.RechirpOf)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -41,32 +128,121 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.ParentID,
+		&i.RootID,
+		&i.DeletedAt,
+		&i.QuoteOf,
+		&i.RechirpOf,
+		&i.SearchVector,
+		&i.PurgedAt,
+		&i.HiddenAt,
 	)
 	return i, err
 }
 
-const deleteChirpById = `-- name: DeleteChirpById :exec
+const deleteExpiredChirps = `-- name: DeleteExpiredChirps :execrows
 DELETE FROM chirps
-WHERE id = $1 AND user_id = $2
+WHERE chirps.id IN (
+  SELECT expired.id FROM chirps AS expired
+  WHERE expired.deleted_at < $1
+  AND NOT EXISTS (
+    SELECT 1 FROM chirps AS referencing
+    WHERE referencing.parent_id = expired.id
+    OR referencing.root_id = expired.id
+    OR referencing.quote_of = expired.id
+  )
+  LIMIT $2
+)
 `
 
-type DeleteChirpByIdParams struct {
-	ID     uuid.UUID
-	UserID uuid.UUID
+type DeleteExpiredChirpsParams struct {
+	DeletedBefore time.Time
+	PageLimit     int32
 }
 
-func (q *Queries) DeleteChirpById(ctx context.Context, arg DeleteChirpByIdParams) error {
-	_, err := q.db.ExecContext(ctx, deleteChirpById, arg.ID, arg.UserID)
+func (q *Queries) DeleteExpiredChirps(ctx context.Context, arg DeleteExpiredChirpsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredChirps, arg.DeletedBefore, arg.PageLimit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteUserRechirp = `-- name: DeleteUserRechirp :exec
+DELETE FROM chirps
+WHERE user_id = $1 AND rechirp_of = $2::uuid
+`
+
+type DeleteUserRechirpParams struct {
+	UserID    uuid.UUID
+	RechirpOf uuid.UUID
+}
+
+func (q *Queries) DeleteUserRechirp(ctx context.Context, arg DeleteUserRechirpParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUserRechirp, arg.UserID, arg.RechirpOf)
 	return err
 }
 
-const getAllChirpsAsc = `-- name: GetAllChirpsAsc :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-ORDER BY chirps.created_at ASC
+const getChirpById = `-- name: GetChirpById :one
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, deleted_at, quote_of, rechirp_of, search_vector, purged_at, hidden_at FROM chirps
+WHERE chirps.id = $1
+`
+
+func (q *Queries) GetChirpById(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpById, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.RootID,
+		&i.DeletedAt,
+		&i.QuoteOf,
+		&i.RechirpOf,
+		&i.SearchVector,
+		&i.PurgedAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const getChirpByIdForUpdate = `-- name: GetChirpByIdForUpdate :one
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, deleted_at, quote_of, rechirp_of, search_vector, purged_at, hidden_at FROM chirps
+WHERE chirps.id = $1
+FOR UPDATE
 `
 
-func (q *Queries) GetAllChirpsAsc(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getAllChirpsAsc)
+func (q *Queries) GetChirpByIdForUpdate(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpByIdForUpdate, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.RootID,
+		&i.DeletedAt,
+		&i.QuoteOf,
+		&i.RechirpOf,
+		&i.SearchVector,
+		&i.PurgedAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const getChirpsByIds = `-- name: GetChirpsByIds :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, deleted_at, quote_of, rechirp_of, search_vector, purged_at, hidden_at FROM chirps
+WHERE chirps.id = ANY($1::uuid[])
+`
+
+func (q *Queries) GetChirpsByIds(ctx context.Context, ids []uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByIds, pq.Array(ids))
 	if err != nil {
 		return nil, err
 	}
@@ -80,6 +256,14 @@ func (q *Queries) GetAllChirpsAsc(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.ParentID,
+			&i.RootID,
+			&i.DeletedAt,
+			&i.QuoteOf,
+			&i.RechirpOf,
+			&i.SearchVector,
+			&i.PurgedAt,
+			&i.HiddenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -94,14 +278,49 @@ func (q *Queries) GetAllChirpsAsc(ctx context.Context) ([]Chirp, error) {
 	return items, nil
 }
 
-const getAllChirpsByIdAsc = `-- name: GetAllChirpsByIdAsc :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-WHERE user_id = $1
-ORDER BY chirps.created_at ASC
+const getChirpsPageAsc = `-- name: GetChirpsPageAsc :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, deleted_at, quote_of, rechirp_of, search_vector, purged_at, hidden_at FROM chirps
+WHERE chirps.deleted_at IS NULL
+AND chirps.hidden_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM restricted_users WHERE restricted_users.id = chirps.user_id
+)
+AND ($1::uuid IS NULL OR chirps.user_id = $1::uuid)
+AND chirps.id IS DISTINCT FROM $2::uuid
+AND NOT EXISTS (
+  SELECT 1 FROM user_blocks
+  WHERE user_blocks.blocker_id = $3 AND user_blocks.blocked_id = chirps.user_id
+)
+AND NOT EXISTS (
+  SELECT 1 FROM user_mutes
+  WHERE user_mutes.muter_id = $3 AND user_mutes.muted_id = chirps.user_id
+)
+AND (
+  $4::timestamp IS NULL
+  OR (chirps.created_at, chirps.id) > ($4::timestamp, $5::uuid)
+)
+ORDER BY chirps.created_at ASC, chirps.id ASC
+LIMIT $6
 `
 
-func (q *Queries) GetAllChirpsByIdAsc(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getAllChirpsByIdAsc, userID)
+type GetChirpsPageAscParams struct {
+	AuthorID        uuid.NullUUID
+	ExcludeID       uuid.NullUUID
+	ViewerID        uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) GetChirpsPageAsc(ctx context.Context, arg GetChirpsPageAscParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsPageAsc,
+		arg.AuthorID,
+		arg.ExcludeID,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -115,6 +334,14 @@ func (q *Queries) GetAllChirpsByIdAsc(ctx context.Context, userID uuid.UUID) ([]
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.ParentID,
+			&i.RootID,
+			&i.DeletedAt,
+			&i.QuoteOf,
+			&i.RechirpOf,
+			&i.SearchVector,
+			&i.PurgedAt,
+			&i.HiddenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -129,13 +356,548 @@ func (q *Queries) GetAllChirpsByIdAsc(ctx context.Context, userID uuid.UUID) ([]
 	return items, nil
 }
 
-const getChirpById = `-- name: GetChirpById :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-WHERE chirps.id = $1
+const getChirpsPageDesc = `-- name: GetChirpsPageDesc :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, deleted_at, quote_of, rechirp_of, search_vector, purged_at, hidden_at FROM chirps
+WHERE chirps.deleted_at IS NULL
+AND chirps.hidden_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM restricted_users WHERE restricted_users.id = chirps.user_id
+)
+AND ($1::uuid IS NULL OR chirps.user_id = $1::uuid)
+AND chirps.id IS DISTINCT FROM $2::uuid
+AND NOT EXISTS (
+  SELECT 1 FROM user_blocks
+  WHERE user_blocks.blocker_id = $3 AND user_blocks.blocked_id = chirps.user_id
+)
+AND NOT EXISTS (
+  SELECT 1 FROM user_mutes
+  WHERE user_mutes.muter_id = $3 AND user_mutes.muted_id = chirps.user_id
+)
+AND (
+  $4::timestamp IS NULL
+  OR (chirps.created_at, chirps.id) < ($4::timestamp, $5::uuid)
+)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT $6
 `
 
-func (q *Queries) GetChirpById(ctx context.Context, id uuid.UUID) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, getChirpById, id)
+type GetChirpsPageDescParams struct {
+	AuthorID        uuid.NullUUID
+	ExcludeID       uuid.NullUUID
+	ViewerID        uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) GetChirpsPageDesc(ctx context.Context, arg GetChirpsPageDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsPageDesc,
+		arg.AuthorID,
+		arg.ExcludeID,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.RootID,
+			&i.DeletedAt,
+			&i.QuoteOf,
+			&i.RechirpOf,
+			&i.SearchVector,
+			&i.PurgedAt,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPinnedChirp = `-- name: GetPinnedChirp :one
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.root_id, chirps.deleted_at, chirps.quote_of, chirps.rechirp_of, chirps.search_vector, chirps.purged_at, chirps.hidden_at FROM chirps
+JOIN users ON users.pinned_chirp_id = chirps.id
+WHERE users.id = $1
+AND chirps.deleted_at IS NULL
+AND chirps.hidden_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM restricted_users WHERE restricted_users.id = chirps.user_id
+)
+AND NOT EXISTS (
+  SELECT 1 FROM user_blocks
+  WHERE user_blocks.blocker_id = $2 AND user_blocks.blocked_id = chirps.user_id
+)
+AND NOT EXISTS (
+  SELECT 1 FROM user_mutes
+  WHERE user_mutes.muter_id = $2 AND user_mutes.muted_id = chirps.user_id
+)
+`
+
+type GetPinnedChirpParams struct {
+	AuthorID uuid.UUID
+	ViewerID uuid.UUID
+}
+
+func (q *Queries) GetPinnedChirp(ctx context.Context, arg GetPinnedChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getPinnedChirp, arg.AuthorID, arg.ViewerID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.RootID,
+		&i.DeletedAt,
+		&i.QuoteOf,
+		&i.RechirpOf,
+		&i.SearchVector,
+		&i.PurgedAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const getQuoteCounts = `-- name: GetQuoteCounts :many
+SELECT quote_of::uuid AS chirp_id, COUNT(*) AS quote_count
+FROM chirps
+WHERE quote_of = ANY($1::uuid[])
+AND deleted_at IS NULL
+GROUP BY quote_of
+`
+
+type GetQuoteCountsRow struct {
+	ChirpID    uuid.UUID
+	QuoteCount int64
+}
+
+func (q *Queries) GetQuoteCounts(ctx context.Context, chirpIds []uuid.UUID) ([]GetQuoteCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getQuoteCounts, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetQuoteCountsRow
+	for rows.Next() {
+		var i GetQuoteCountsRow
+		if err := rows.Scan(
+			&i.ChirpID,
+			&i.QuoteCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRechirpCounts = `-- name: GetRechirpCounts :many
+SELECT rechirp_of::uuid AS chirp_id, COUNT(*) AS rechirp_count
+FROM chirps
+WHERE rechirp_of = ANY($1::uuid[])
+AND deleted_at IS NULL
+GROUP BY rechirp_of
+`
+
+type GetRechirpCountsRow struct {
+	ChirpID      uuid.UUID
+	RechirpCount int64
+}
+
+func (q *Queries) GetRechirpCounts(ctx context.Context, chirpIds []uuid.UUID) ([]GetRechirpCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRechirpCounts, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRechirpCountsRow
+	for rows.Next() {
+		var i GetRechirpCountsRow
+		if err := rows.Scan(
+			&i.ChirpID,
+			&i.RechirpCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getThreadChirps = `-- name: GetThreadChirps :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, deleted_at, quote_of, rechirp_of, search_vector, purged_at, hidden_at FROM chirps
+WHERE chirps.id = $1 OR chirps.root_id = $1
+ORDER BY chirps.created_at ASC, chirps.id ASC
+`
+
+func (q *Queries) GetThreadChirps(ctx context.Context, id uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getThreadChirps, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.RootID,
+			&i.DeletedAt,
+			&i.QuoteOf,
+			&i.RechirpOf,
+			&i.SearchVector,
+			&i.PurgedAt,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTrashPage = `-- name: GetTrashPage :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, deleted_at, quote_of, rechirp_of, search_vector, purged_at, hidden_at FROM chirps
+WHERE chirps.user_id = $1
+AND chirps.deleted_at >= $2
+AND chirps.purged_at IS NULL
+AND chirps.rechirp_of IS NULL
+AND (
+  $3::timestamp IS NULL
+  OR (chirps.deleted_at, chirps.id) < ($3::timestamp, $4::uuid)
+)
+ORDER BY chirps.deleted_at DESC, chirps.id DESC
+LIMIT $5
+`
+
+type GetTrashPageParams struct {
+	UserID          uuid.UUID
+	DeletedAfter    time.Time
+	CursorDeletedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) GetTrashPage(ctx context.Context, arg GetTrashPageParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getTrashPage,
+		arg.UserID,
+		arg.DeletedAfter,
+		arg.CursorDeletedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.RootID,
+			&i.DeletedAt,
+			&i.QuoteOf,
+			&i.RechirpOf,
+			&i.SearchVector,
+			&i.PurgedAt,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserRechirp = `-- name: GetUserRechirp :one
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, deleted_at, quote_of, rechirp_of, search_vector, purged_at, hidden_at FROM chirps
+WHERE chirps.user_id = $1 AND chirps.rechirp_of = $2::uuid
+`
+
+type GetUserRechirpParams struct {
+	UserID    uuid.UUID
+	RechirpOf uuid.UUID
+}
+
+func (q *Queries) GetUserRechirp(ctx context.Context, arg GetUserRechirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getUserRechirp, arg.UserID, arg.RechirpOf)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.RootID,
+		&i.DeletedAt,
+		&i.QuoteOf,
+		&i.RechirpOf,
+		&i.SearchVector,
+		&i.PurgedAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const hideChirp = `-- name: HideChirp :execrows
+UPDATE chirps
+SET hidden_at = NOW()
+WHERE id = $1 AND hidden_at IS NULL
+`
+
+func (q *Queries) HideChirp(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, hideChirp, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const purgeExpiredChirps = `-- name: PurgeExpiredChirps :many
+UPDATE chirps
+SET body = '', purged_at = NOW()
+WHERE chirps.id IN (
+  SELECT expired.id FROM chirps AS expired
+  WHERE expired.deleted_at < $1
+  AND expired.purged_at IS NULL
+  LIMIT $2
+)
+RETURNING chirps.id
+`
+
+type PurgeExpiredChirpsParams struct {
+	DeletedBefore time.Time
+	PageLimit     int32
+}
+
+func (q *Queries) PurgeExpiredChirps(ctx context.Context, arg PurgeExpiredChirpsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, purgeExpiredChirps, arg.DeletedBefore, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreChirp = `-- name: RestoreChirp :exec
+UPDATE chirps
+SET deleted_at = NULL
+WHERE (id = $1 OR rechirp_of = $1)
+AND deleted_at = $2
+AND purged_at IS NULL
+`
+
+type RestoreChirpParams struct {
+	ID        uuid.UUID
+	DeletedAt time.Time
+}
+
+func (q *Queries) RestoreChirp(ctx context.Context, arg RestoreChirpParams) error {
+	_, err := q.db.ExecContext(ctx, restoreChirp, arg.ID, arg.DeletedAt)
+	return err
+}
+
+const searchChirps = `-- name: SearchChirps :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.root_id, chirps.deleted_at, chirps.quote_of, chirps.rechirp_of, chirps.search_vector, chirps.purged_at, chirps.hidden_at, ts_rank(chirps.search_vector, to_tsquery('english', $1))::real AS rank
+FROM chirps
+WHERE chirps.search_vector @@ to_tsquery('english', $1)
+AND chirps.deleted_at IS NULL
+AND chirps.hidden_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM restricted_users WHERE restricted_users.id = chirps.user_id
+)
+AND ($2::uuid IS NULL OR chirps.user_id = $2::uuid)
+AND NOT EXISTS (
+  SELECT 1 FROM user_blocks
+  WHERE user_blocks.blocker_id = $3 AND user_blocks.blocked_id = chirps.user_id
+)
+AND NOT EXISTS (
+  SELECT 1 FROM user_mutes
+  WHERE user_mutes.muter_id = $3 AND user_mutes.muted_id = chirps.user_id
+)
+AND ($4::timestamp IS NULL OR chirps.created_at >= $4::timestamp)
+AND ($5::timestamp IS NULL OR chirps.created_at < $5::timestamp)
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT $6 OFFSET $7
+`
+
+type SearchChirpsParams struct {
+	Query      string
+	AuthorID   uuid.NullUUID
+	ViewerID   uuid.UUID
+	Since      sql.NullTime
+	Until      sql.NullTime
+	PageLimit  int32
+	PageOffset int32
+}
+
+type SearchChirpsRow struct {
+	Chirp Chirp
+	Rank  float32
+}
+
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]SearchChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirps,
+		arg.Query,
+		arg.AuthorID,
+		arg.ViewerID,
+		arg.Since,
+		arg.Until,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsRow
+	for rows.Next() {
+		var i SearchChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.ParentID,
+			&i.Chirp.RootID,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.QuoteOf,
+			&i.Chirp.RechirpOf,
+			&i.Chirp.SearchVector,
+			&i.Chirp.PurgedAt,
+			&i.Chirp.HiddenAt,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const softDeleteChirp = `-- name: SoftDeleteChirp :exec
+UPDATE chirps
+SET deleted_at = $1
+WHERE (id = $2 OR rechirp_of = $2)
+AND deleted_at IS NULL
+`
+
+type SoftDeleteChirpParams struct {
+	DeletedAt time.Time
+	ID        uuid.UUID
+}
+
+func (q *Queries) SoftDeleteChirp(ctx context.Context, arg SoftDeleteChirpParams) error {
+	_, err := q.db.ExecContext(ctx, softDeleteChirp, arg.DeletedAt, arg.ID)
+	return err
+}
+
+const unhideChirp = `-- name: UnhideChirp :execrows
+UPDATE chirps
+SET hidden_at = NULL
+WHERE id = $1 AND hidden_at IS NOT NULL
+`
+
+func (q *Queries) UnhideChirp(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unhideChirp, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING id, created_at, updated_at, body, user_id, parent_id, root_id, deleted_at, quote_of, rechirp_of, search_vector, purged_at, hidden_at
+`
+
+type UpdateChirpBodyParams struct {
+	Body string
+	ID   uuid.UUID
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody, arg.Body, arg.ID)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -143,6 +905,14 @@ func (q *Queries) GetChirpById(ctx context.Context, id uuid.UUID) (Chirp, error)
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.ParentID,
+		&i.RootID,
+		&i.DeletedAt,
+		&i.QuoteOf,
+		&i.RechirpOf,
+		&i.SearchVector,
+		&i.PurgedAt,
+		&i.HiddenAt,
 	)
 	return i, err
 }
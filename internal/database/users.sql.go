@@ -7,8 +7,10 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createUser = `-- name: CreateUser :one
@@ -17,24 +19,27 @@ INSERT INTO users (
   created_at,
   updated_at,
   email,
-  hashed_password
+  hashed_password,
+  handle
 ) VALUES (
   gen_random_uuid(),
   NOW(),
   NOW(),
   $1,
-  $2
+  $2,
+  $3
 )
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id, is_admin, account_status, status_reason, status_expires_at, avatar_upload_id
 `
 
 type CreateUserParams struct {
 	Email          string
 	HashedPassword string
+	Handle         sql.NullString
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword)
+	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword, arg.Handle)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -43,6 +48,16 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.AccountStatus,
+		&i.StatusReason,
+		&i.StatusExpiresAt,
+		&i.AvatarUploadID,
 	)
 	return i, err
 }
@@ -56,8 +71,30 @@ func (q *Queries) DeleteAllUsers(ctx context.Context) error {
 	return err
 }
 
+const getUserAccountStatus = `-- name: GetUserAccountStatus :one
+SELECT account_status, status_reason, status_expires_at FROM users
+WHERE users.id = $1
+`
+
+type GetUserAccountStatusRow struct {
+	AccountStatus   string
+	StatusReason    string
+	StatusExpiresAt sql.NullTime
+}
+
+func (q *Queries) GetUserAccountStatus(ctx context.Context, id uuid.UUID) (GetUserAccountStatusRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserAccountStatus, id)
+	var i GetUserAccountStatusRow
+	err := row.Scan(
+		&i.AccountStatus,
+		&i.StatusReason,
+		&i.StatusExpiresAt,
+	)
+	return i, err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id, is_admin, account_status, status_reason, status_expires_at, avatar_upload_id FROM users
 where users.email = $1
 `
 
@@ -71,6 +108,286 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.AccountStatus,
+		&i.StatusReason,
+		&i.StatusExpiresAt,
+		&i.AvatarUploadID,
+	)
+	return i, err
+}
+
+const getUserByHandle = `-- name: GetUserByHandle :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id, is_admin, account_status, status_reason, status_expires_at, avatar_upload_id FROM users
+WHERE LOWER(users.handle) = LOWER($1)
+`
+
+func (q *Queries) GetUserByHandle(ctx context.Context, handle string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByHandle, handle)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.AccountStatus,
+		&i.StatusReason,
+		&i.StatusExpiresAt,
+		&i.AvatarUploadID,
+	)
+	return i, err
+}
+
+const getUserById = `-- name: GetUserById :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id, is_admin, account_status, status_reason, status_expires_at, avatar_upload_id FROM users
+WHERE users.id = $1
+`
+
+func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserById, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.AccountStatus,
+		&i.StatusReason,
+		&i.StatusExpiresAt,
+		&i.AvatarUploadID,
+	)
+	return i, err
+}
+
+const getUserByIdForUpdate = `-- name: GetUserByIdForUpdate :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id, is_admin, account_status, status_reason, status_expires_at, avatar_upload_id FROM users
+WHERE users.id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetUserByIdForUpdate(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByIdForUpdate, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.AccountStatus,
+		&i.StatusReason,
+		&i.StatusExpiresAt,
+		&i.AvatarUploadID,
+	)
+	return i, err
+}
+
+const getUsersByHandles = `-- name: GetUsersByHandles :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id, is_admin, account_status, status_reason, status_expires_at, avatar_upload_id FROM users
+WHERE LOWER(users.handle) = ANY($1::text[])
+`
+
+func (q *Queries) GetUsersByHandles(ctx context.Context, handles []string) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByHandles, pq.Array(handles))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.Handle,
+			&i.DisplayName,
+			&i.Bio,
+			&i.AvatarUrl,
+			&i.PinnedChirpID,
+			&i.IsAdmin,
+			&i.AccountStatus,
+			&i.StatusReason,
+			&i.StatusExpiresAt,
+			&i.AvatarUploadID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUsersByIds = `-- name: GetUsersByIds :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id, is_admin, account_status, status_reason, status_expires_at, avatar_upload_id FROM users
+WHERE users.id = ANY($1::uuid[])
+`
+
+func (q *Queries) GetUsersByIds(ctx context.Context, ids []uuid.UUID) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByIds, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.Handle,
+			&i.DisplayName,
+			&i.Bio,
+			&i.AvatarUrl,
+			&i.PinnedChirpID,
+			&i.IsAdmin,
+			&i.AccountStatus,
+			&i.StatusReason,
+			&i.StatusExpiresAt,
+			&i.AvatarUploadID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const pinUserChirp = `-- name: PinUserChirp :exec
+UPDATE users
+SET pinned_chirp_id = $1, updated_at = NOW()
+WHERE id = $2
+`
+
+type PinUserChirpParams struct {
+	PinnedChirpID uuid.NullUUID
+	ID            uuid.UUID
+}
+
+func (q *Queries) PinUserChirp(ctx context.Context, arg PinUserChirpParams) error {
+	_, err := q.db.ExecContext(ctx, pinUserChirp, arg.PinnedChirpID, arg.ID)
+	return err
+}
+
+const setUserAccountStatus = `-- name: SetUserAccountStatus :exec
+UPDATE users
+SET account_status = $1, status_reason = $2, status_expires_at = $3, updated_at = NOW()
+WHERE id = $4
+`
+
+type SetUserAccountStatusParams struct {
+	AccountStatus   string
+	StatusReason    string
+	StatusExpiresAt sql.NullTime
+	ID              uuid.UUID
+}
+
+func (q *Queries) SetUserAccountStatus(ctx context.Context, arg SetUserAccountStatusParams) error {
+	_, err := q.db.ExecContext(ctx, setUserAccountStatus,
+		arg.AccountStatus,
+		arg.StatusReason,
+		arg.StatusExpiresAt,
+		arg.ID,
+	)
+	return err
+}
+
+const unpinUserChirp = `-- name: UnpinUserChirp :exec
+UPDATE users
+SET pinned_chirp_id = NULL, updated_at = NOW()
+WHERE id = $1 AND pinned_chirp_id = $2
+`
+
+type UnpinUserChirpParams struct {
+	ID            uuid.UUID
+	PinnedChirpID uuid.NullUUID
+}
+
+func (q *Queries) UnpinUserChirp(ctx context.Context, arg UnpinUserChirpParams) error {
+	_, err := q.db.ExecContext(ctx, unpinUserChirp, arg.ID, arg.PinnedChirpID)
+	return err
+}
+
+const updateUserAvatarUrl = `-- name: UpdateUserAvatarUrl :one
+UPDATE users
+SET avatar_url = $1, avatar_upload_id = $2, updated_at = NOW()
+WHERE id = $3
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id, is_admin, account_status, status_reason, status_expires_at, avatar_upload_id
+`
+
+type UpdateUserAvatarUrlParams struct {
+	AvatarUrl      string
+	AvatarUploadID uuid.NullUUID
+	ID             uuid.UUID
+}
+
+func (q *Queries) UpdateUserAvatarUrl(ctx context.Context, arg UpdateUserAvatarUrlParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserAvatarUrl, arg.AvatarUrl, arg.AvatarUploadID, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.AccountStatus,
+		&i.StatusReason,
+		&i.StatusExpiresAt,
+		&i.AvatarUploadID,
 	)
 	return i, err
 }
@@ -79,7 +396,7 @@ const updateUserEmailPassword = `-- name: UpdateUserEmailPassword :one
 UPDATE users
 SET email = $1, hashed_password = $2
 WHERE id = $3
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id, is_admin, account_status, status_reason, status_expires_at, avatar_upload_id
 `
 
 type UpdateUserEmailPasswordParams struct {
@@ -98,6 +415,98 @@ func (q *Queries) UpdateUserEmailPassword(ctx context.Context, arg UpdateUserEma
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.AccountStatus,
+		&i.StatusReason,
+		&i.StatusExpiresAt,
+		&i.AvatarUploadID,
+	)
+	return i, err
+}
+
+const updateUserHandle = `-- name: UpdateUserHandle :one
+UPDATE users
+SET handle = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id, is_admin, account_status, status_reason, status_expires_at, avatar_upload_id
+`
+
+type UpdateUserHandleParams struct {
+	Handle sql.NullString
+	ID     uuid.UUID
+}
+
+func (q *Queries) UpdateUserHandle(ctx context.Context, arg UpdateUserHandleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserHandle, arg.Handle, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.AccountStatus,
+		&i.StatusReason,
+		&i.StatusExpiresAt,
+		&i.AvatarUploadID,
+	)
+	return i, err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users
+SET handle = $1, display_name = $2, bio = $3, avatar_url = $4, updated_at = NOW(),
+  avatar_upload_id = CASE WHEN users.avatar_url = $4 THEN users.avatar_upload_id END
+WHERE id = $5
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id, is_admin, account_status, status_reason, status_expires_at, avatar_upload_id
+`
+
+type UpdateUserProfileParams struct {
+	Handle      sql.NullString
+	DisplayName string
+	Bio         string
+	AvatarUrl   string
+	ID          uuid.UUID
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserProfile,
+		arg.Handle,
+		arg.DisplayName,
+		arg.Bio,
+		arg.AvatarUrl,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.AccountStatus,
+		&i.StatusReason,
+		&i.StatusExpiresAt,
+		&i.AvatarUploadID,
 	)
 	return i, err
 }
@@ -105,8 +514,8 @@ func (q *Queries) UpdateUserEmailPassword(ctx context.Context, arg UpdateUserEma
 const upgradeUserChirpyRed = `-- name: UpgradeUserChirpyRed :one
 UPDATE users
 SET is_chirpy_red = true
-WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+WHERE id = $1 AND NOT is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id, is_admin, account_status, status_reason, status_expires_at, avatar_upload_id
 `
 
 func (q *Queries) UpgradeUserChirpyRed(ctx context.Context, id uuid.UUID) (User, error) {
@@ -119,6 +528,16 @@ func (q *Queries) UpgradeUserChirpyRed(ctx context.Context, id uuid.UUID) (User,
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.PinnedChirpID,
+		&i.IsAdmin,
+		&i.AccountStatus,
+		&i.StatusReason,
+		&i.StatusExpiresAt,
+		&i.AvatarUploadID,
 	)
 	return i, err
 }
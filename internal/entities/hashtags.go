@@ -0,0 +1,52 @@
+package entities
+
+import (
+	"strings"
+	"unicode"
+)
+
+const maxHashtagLength = 100
+
+// ExtractHashtags returns the distinct, lowercased tags in body in the
+// order they first appear. A tag is a # that does not follow a word
+// character, followed by letters, digits or underscores with at least
+// one letter, so "#1" and "a#b" are not tags.
+func ExtractHashtags(body string) []string {
+	runes := []rune(body)
+	seen := map[string]bool{}
+	tags := []string{}
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '#' || (i > 0 && isTagRune(runes[i-1])) {
+			continue
+		}
+
+		end := i + 1
+		hasLetter := false
+		for end < len(runes) && isTagRune(runes[end]) {
+			if unicode.IsLetter(runes[end]) {
+				hasLetter = true
+			}
+			end++
+		}
+
+		tag := NormalizeHashtag(string(runes[i+1 : end]))
+		i = end - 1
+		if !hasLetter || len([]rune(tag)) > maxHashtagLength || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
+
+func NormalizeHashtag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(tag, "#"))
+}
+
+func isTagRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
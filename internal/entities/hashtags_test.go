@@ -0,0 +1,66 @@
+package entities
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractHashtags(t *testing.T) {
+	type Case struct {
+		name string
+		body string
+		want []string
+	}
+
+	cases := []Case{
+		{
+			name: "No hashtags",
+			body: "just a regular chirp",
+			want: []string{},
+		},
+		{
+			name: "Single hashtag",
+			body: "learning #golang today",
+			want: []string{"golang"},
+		},
+		{
+			name: "Lowercased and deduplicated",
+			body: "#Go #go #GO #gophers",
+			want: []string{"go", "gophers"},
+		},
+		{
+			name: "Punctuation ends a tag",
+			body: "what a day! #mondays, #coffee.",
+			want: []string{"mondays", "coffee"},
+		},
+		{
+			name: "Numbers only are not tags",
+			body: "we're #1 and #2024 but #web3",
+			want: []string{"web3"},
+		},
+		{
+			name: "Must not follow a word character",
+			body: "issue#42 email@x#y #ok",
+			want: []string{"ok"},
+		},
+		{
+			name: "Unicode letters",
+			body: "#Café #東京",
+			want: []string{"café", "東京"},
+		},
+		{
+			name: "Bare hash",
+			body: "# #",
+			want: []string{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := ExtractHashtags(c.body)
+			if !slices.Equal(got, c.want) {
+				t.Errorf("ExtractHashtags() got = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
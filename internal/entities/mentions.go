@@ -0,0 +1,107 @@
+package entities
+
+import (
+	"strings"
+	"unicode"
+)
+
+const MaxHandleLength = 15
+
+type Mention struct {
+	Handle string
+	// Start and End are offsets in Unicode code points, End exclusive,
+	// covering the @ and the handle.
+	Start int
+	End   int
+}
+
+// ExtractMentions returns every @handle in body. An @ that follows a
+// word character (as in an email address) is not a mention, and runs
+// longer than MaxHandleLength are ignored rather than truncated. Neither
+// is a handle that runs straight into a non-ASCII letter or digit, so
+// "@José" does not mention "jos".
+func ExtractMentions(body string) []Mention {
+	runes := []rune(body)
+	mentions := []Mention{}
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '@' || (i > 0 && isWordRune(runes[i-1])) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isHandleRune(runes[end]) {
+			end++
+		}
+
+		handle := string(runes[i+1 : end])
+		truncated := end < len(runes) && isWordRune(runes[end])
+		if handle != "" && len(handle) <= MaxHandleLength && !truncated {
+			mentions = append(mentions, Mention{
+				Handle: handle,
+				Start:  i,
+				End:    end,
+			})
+		}
+		i = end - 1
+	}
+
+	return mentions
+}
+
+func NormalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(handle, "@"))
+}
+
+func ValidHandle(handle string) bool {
+	if handle == "" || len(handle) > MaxHandleLength {
+		return false
+	}
+
+	for _, r := range handle {
+		if !isHandleRune(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// reservedHandles would collide with routes such as /api/users/me or
+// impersonate staff, so nobody may claim them.
+var reservedHandles = map[string]bool{
+	"about":         true,
+	"admin":         true,
+	"administrator": true,
+	"api":           true,
+	"app":           true,
+	"chirpy":        true,
+	"everyone":      true,
+	"help":          true,
+	"here":          true,
+	"login":         true,
+	"logout":        true,
+	"me":            true,
+	"mod":           true,
+	"moderator":     true,
+	"null":          true,
+	"root":          true,
+	"security":      true,
+	"settings":      true,
+	"signup":        true,
+	"staff":         true,
+	"support":       true,
+	"system":        true,
+}
+
+func IsReservedHandle(handle string) bool {
+	return reservedHandles[NormalizeHandle(handle)]
+}
+
+func isHandleRune(r rune) bool {
+	return r == '_' || (r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)))
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
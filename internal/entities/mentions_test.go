@@ -0,0 +1,115 @@
+package entities
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractMentions(t *testing.T) {
+	type Case struct {
+		name string
+		body string
+		want []Mention
+	}
+
+	cases := []Case{
+		{
+			name: "No mentions",
+			body: "nobody here",
+			want: []Mention{},
+		},
+		{
+			name: "Single mention",
+			body: "hi @boots!",
+			want: []Mention{{Handle: "boots", Start: 3, End: 9}},
+		},
+		{
+			name: "Offsets count code points",
+			body: "héllo 👋 @Lane_W",
+			want: []Mention{{Handle: "Lane_W", Start: 8, End: 15}},
+		},
+		{
+			name: "Email addresses are not mentions",
+			body: "mail me at boots@example.com",
+			want: []Mention{},
+		},
+		{
+			name: "Too long",
+			body: "@abcdefghijklmnop @ok",
+			want: []Mention{{Handle: "ok", Start: 18, End: 21}},
+		},
+		{
+			name: "Handles running into non-ASCII letters are dropped",
+			body: "hola @José and @Ана, cc @boots",
+			want: []Mention{{Handle: "boots", Start: 24, End: 30}},
+		},
+		{
+			name: "Non-ASCII word before the @",
+			body: "café@boots",
+			want: []Mention{},
+		},
+		{
+			name: "Repeated mentions are all returned",
+			body: "@a @a",
+			want: []Mention{{Handle: "a", Start: 0, End: 2}, {Handle: "a", Start: 3, End: 5}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := ExtractMentions(c.body)
+			if !slices.Equal(got, c.want) {
+				t.Errorf("ExtractMentions() got = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestValidHandle(t *testing.T) {
+	type Case struct {
+		name   string
+		handle string
+		want   bool
+	}
+
+	cases := []Case{
+		{name: "Letters and digits", handle: "boots42", want: true},
+		{name: "Underscore", handle: "lane_w", want: true},
+		{name: "Empty", handle: "", want: false},
+		{name: "Too long", handle: "abcdefghijklmnop", want: false},
+		{name: "Punctuation", handle: "boots!", want: false},
+		{name: "Non-ASCII", handle: "bööts", want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ValidHandle(c.handle); got != c.want {
+				t.Errorf("ValidHandle(%q) got = %v, want %v", c.handle, got, c.want)
+			}
+		})
+	}
+}
+
+func TestIsReservedHandle(t *testing.T) {
+	type Case struct {
+		name   string
+		handle string
+		want   bool
+	}
+
+	cases := []Case{
+		{name: "Reserved", handle: "admin", want: true},
+		{name: "Reserved any case", handle: "Me", want: true},
+		{name: "Reserved with @", handle: "@support", want: true},
+		{name: "Ordinary", handle: "boots42", want: false},
+		{name: "Contains reserved word", handle: "admin_fan", want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := IsReservedHandle(c.handle); got != c.want {
+				t.Errorf("IsReservedHandle(%q) got = %v, want %v", c.handle, got, c.want)
+			}
+		})
+	}
+}
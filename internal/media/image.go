@@ -0,0 +1,184 @@
+// Package media validates uploaded images and prepares them for storage.
+// Every image is turned upright by its EXIF orientation, then decoded and
+// re-encoded, which drops EXIF and any other metadata, and a thumbnail is
+// generated alongside it.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+)
+
+const (
+	DefaultMaxBytes   = 5 << 20
+	MaxDimension      = 8000
+	MaxPixels         = 40_000_000
+	ThumbnailSize     = 320
+	jpegEncodeQuality = 85
+)
+
+var (
+	ErrTooLarge           = errors.New("image is too large")
+	ErrUnsupportedType    = errors.New("unsupported image type")
+	ErrDimensionsTooLarge = fmt.Errorf("image dimensions must be at most %dx%d", MaxDimension, MaxDimension)
+	ErrTooManyPixels      = fmt.Errorf("image must be at most %d megapixels", MaxPixels/1_000_000)
+)
+
+// AllowedContentTypes maps sniffed MIME types to the extension stored files
+// get. GIFs are flattened to their first frame and stored as PNG.
+var AllowedContentTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "png",
+}
+
+type Image struct {
+	ContentType string
+	Extension   string
+	Data        []byte
+	Width       int
+	Height      int
+}
+
+type Processed struct {
+	Original  Image
+	Thumbnail Image
+}
+
+// Process reads at most maxBytes from r, checks that it really is a
+// supported image by sniffing its content rather than trusting the
+// client's Content-Type, and returns a clean copy plus a thumbnail.
+func Process(r io.Reader, maxBytes int64) (Processed, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return Processed{}, fmt.Errorf("Error reading image: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return Processed{}, ErrTooLarge
+	}
+
+	sniffed := http.DetectContentType(data)
+	if _, ok := AllowedContentTypes[sniffed]; !ok {
+		return Processed{}, fmt.Errorf("%w: %s", ErrUnsupportedType, sniffed)
+	}
+
+	// Check dimensions before decoding so a tiny file cannot claim a huge
+	// canvas and exhaust memory.
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Processed{}, fmt.Errorf("Error reading image header: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width > MaxDimension || config.Height > MaxDimension {
+		return Processed{}, ErrDimensionsTooLarge
+	}
+	if config.Width*config.Height > MaxPixels {
+		return Processed{}, ErrTooManyPixels
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Processed{}, fmt.Errorf("Error decoding image: %w", err)
+	}
+	if sniffed == "image/jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+
+	contentType := sniffed
+	if contentType == "image/gif" {
+		contentType = "image/png"
+	}
+
+	original, err := encode(img, contentType)
+	if err != nil {
+		return Processed{}, err
+	}
+
+	thumbnail, err := encode(Thumbnail(img, ThumbnailSize), contentType)
+	if err != nil {
+		return Processed{}, err
+	}
+
+	return Processed{Original: original, Thumbnail: thumbnail}, nil
+}
+
+func encode(img image.Image, contentType string) (Image, error) {
+	var buf bytes.Buffer
+	var err error
+	switch contentType {
+	case "image/jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegEncodeQuality})
+	case "image/png":
+		err = png.Encode(&buf, img)
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+	if err != nil {
+		return Image{}, fmt.Errorf("Error encoding image: %w", err)
+	}
+
+	bounds := img.Bounds()
+	return Image{
+		ContentType: contentType,
+		Extension:   AllowedContentTypes[contentType],
+		Data:        buf.Bytes(),
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+	}, nil
+}
+
+// Thumbnail scales img to fit within size x size, keeping its aspect
+// ratio. Each destination pixel averages the source pixels it covers, which
+// avoids the aliasing of nearest-neighbour sampling. Images that already
+// fit are returned unchanged.
+func Thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW <= size && srcH <= size {
+		return img
+	}
+
+	dstW, dstH := size, size
+	if srcW > srcH {
+		dstH = max(1, srcH*size/srcW)
+	} else {
+		dstW = max(1, srcW*size/srcH)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*srcH/dstH
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcH/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*srcW/dstW
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcW/dstW)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
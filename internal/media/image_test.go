@@ -0,0 +1,196 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeGIF(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withEXIF inserts an APP1 segment straight after the JPEG SOI marker.
+func withEXIF(data []byte, payload []byte) []byte {
+	segment := []byte{0xFF, 0xE1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+// orientationEXIF is a big-endian Exif payload whose IFD0 holds only the
+// Orientation tag.
+func orientationEXIF(orientation uint16) []byte {
+	return []byte{
+		'E', 'x', 'i', 'f', 0, 0,
+		'M', 'M', 0, 42, 0, 0, 0, 8,
+		0, 1,
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, byte(orientation >> 8), byte(orientation), 0, 0,
+		0, 0, 0, 0,
+	}
+}
+
+func TestProcess(t *testing.T) {
+	type Case struct {
+		name         string
+		data         []byte
+		maxBytes     int64
+		wantErr      error
+		wantType     string
+		wantW, wantH int
+		wantThumbW   int
+		wantThumbH   int
+	}
+
+	cases := []Case{
+		{
+			name:       "Small PNG",
+			data:       encodePNG(t, testImage(40, 20)),
+			maxBytes:   DefaultMaxBytes,
+			wantType:   "image/png",
+			wantW:      40,
+			wantH:      20,
+			wantThumbW: 40,
+			wantThumbH: 20,
+		},
+		{
+			name:       "Wide JPEG is thumbnailed",
+			data:       encodeJPEG(t, testImage(640, 320)),
+			maxBytes:   DefaultMaxBytes,
+			wantType:   "image/jpeg",
+			wantW:      640,
+			wantH:      320,
+			wantThumbW: 320,
+			wantThumbH: 160,
+		},
+		{
+			name:       "Tall GIF becomes PNG",
+			data:       encodeGIF(t, testImage(100, 400)),
+			maxBytes:   DefaultMaxBytes,
+			wantType:   "image/png",
+			wantW:      100,
+			wantH:      400,
+			wantThumbW: 80,
+			wantThumbH: 320,
+		},
+		{
+			name:       "JPEG rotated by its EXIF orientation",
+			data:       withEXIF(encodeJPEG(t, testImage(640, 320)), orientationEXIF(6)),
+			maxBytes:   DefaultMaxBytes,
+			wantType:   "image/jpeg",
+			wantW:      320,
+			wantH:      640,
+			wantThumbW: 160,
+			wantThumbH: 320,
+		},
+		{
+			name:     "Too many bytes",
+			data:     encodePNG(t, testImage(40, 20)),
+			maxBytes: 10,
+			wantErr:  ErrTooLarge,
+		},
+		{
+			name:     "Not an image",
+			data:     []byte("<html><body>hello</body></html>"),
+			maxBytes: DefaultMaxBytes,
+			wantErr:  ErrUnsupportedType,
+		},
+		{
+			name:     "Dimensions too large",
+			data:     encodePNG(t, image.NewGray(image.Rect(0, 0, MaxDimension+1, 1))),
+			maxBytes: DefaultMaxBytes,
+			wantErr:  ErrDimensionsTooLarge,
+		},
+		{
+			name:     "Too many pixels",
+			data:     encodePNG(t, image.NewGray(image.Rect(0, 0, 6400, 6400))),
+			maxBytes: DefaultMaxBytes,
+			wantErr:  ErrTooManyPixels,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := Process(bytes.NewReader(c.data), c.maxBytes)
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("Process() error = %v, want %v", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if got.Original.ContentType != c.wantType || got.Thumbnail.ContentType != c.wantType {
+				t.Errorf("content types = %v, %v, want %v", got.Original.ContentType, got.Thumbnail.ContentType, c.wantType)
+			}
+			if got.Original.Width != c.wantW || got.Original.Height != c.wantH {
+				t.Errorf("original = %dx%d, want %dx%d", got.Original.Width, got.Original.Height, c.wantW, c.wantH)
+			}
+			if got.Thumbnail.Width != c.wantThumbW || got.Thumbnail.Height != c.wantThumbH {
+				t.Errorf("thumbnail = %dx%d, want %dx%d", got.Thumbnail.Width, got.Thumbnail.Height, c.wantThumbW, c.wantThumbH)
+			}
+
+			config, _, err := image.DecodeConfig(bytes.NewReader(got.Thumbnail.Data))
+			if err != nil || config.Width != c.wantThumbW || config.Height != c.wantThumbH {
+				t.Errorf("encoded thumbnail = %dx%d, %v", config.Width, config.Height, err)
+			}
+		})
+	}
+}
+
+func TestProcessStripsEXIF(t *testing.T) {
+	data := withEXIF(encodeJPEG(t, testImage(64, 64)), []byte("Exif\x00\x00GPS-SECRET-LOCATION"))
+	if !bytes.Contains(data, []byte("GPS-SECRET-LOCATION")) {
+		t.Fatal("test input is missing its EXIF segment")
+	}
+
+	got, err := Process(bytes.NewReader(data), DefaultMaxBytes)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	for _, img := range []Image{got.Original, got.Thumbnail} {
+		if bytes.Contains(img.Data, []byte("Exif")) || bytes.Contains(img.Data, []byte("GPS-SECRET-LOCATION")) {
+			t.Errorf("output still contains EXIF metadata")
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package media
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestJPEGOrientation(t *testing.T) {
+	type Case struct {
+		name string
+		data []byte
+		want int
+	}
+
+	jpeg := encodeJPEG(t, testImage(8, 8))
+	cases := []Case{
+		{name: "No EXIF", data: jpeg, want: 1},
+		{name: "Rotated", data: withEXIF(jpeg, orientationEXIF(6)), want: 6},
+		{name: "Mirrored", data: withEXIF(jpeg, orientationEXIF(2)), want: 2},
+		{name: "Out of range", data: withEXIF(jpeg, orientationEXIF(9)), want: 1},
+		{name: "Not TIFF", data: withEXIF(jpeg, []byte("Exif\x00\x00GPS-SECRET-LOCATION")), want: 1},
+		{name: "Truncated", data: withEXIF(jpeg, orientationEXIF(6))[:20], want: 1},
+		{name: "Not a JPEG", data: []byte("GIF89a"), want: 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := jpegOrientation(c.data)
+			if got != c.want {
+				t.Errorf("jpegOrientation() = %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 3x2 image whose top-left pixel is marked, so we can see where
+	// each orientation moves it.
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	marker := color.RGBA{R: 255, A: 255}
+	src.Set(0, 0, marker)
+
+	type Case struct {
+		orientation  int
+		wantW, wantH int
+		wantX, wantY int
+	}
+
+	cases := []Case{
+		{orientation: 1, wantW: 3, wantH: 2, wantX: 0, wantY: 0},
+		{orientation: 2, wantW: 3, wantH: 2, wantX: 2, wantY: 0},
+		{orientation: 3, wantW: 3, wantH: 2, wantX: 2, wantY: 1},
+		{orientation: 4, wantW: 3, wantH: 2, wantX: 0, wantY: 1},
+		{orientation: 5, wantW: 2, wantH: 3, wantX: 0, wantY: 0},
+		{orientation: 6, wantW: 2, wantH: 3, wantX: 1, wantY: 0},
+		{orientation: 7, wantW: 2, wantH: 3, wantX: 1, wantY: 2},
+		{orientation: 8, wantW: 2, wantH: 3, wantX: 0, wantY: 2},
+	}
+
+	for _, c := range cases {
+		got := orient(src, c.orientation)
+		bounds := got.Bounds()
+		if bounds.Dx() != c.wantW || bounds.Dy() != c.wantH {
+			t.Errorf("orient(%d) = %dx%d, want %dx%d", c.orientation, bounds.Dx(), bounds.Dy(), c.wantW, c.wantH)
+			continue
+		}
+		if got.At(c.wantX, c.wantY) != color.Color(marker) {
+			t.Errorf("orient(%d) did not move the top-left pixel to (%d, %d)", c.orientation, c.wantX, c.wantY)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Package moderation masks blocked words in user-submitted text.
+package moderation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Mode controls where a word may match.
+type Mode string
+
+const (
+	// ModeWholeWord only matches when the word is not part of a longer
+	// word, so blocking "ass" leaves "class" alone.
+	ModeWholeWord Mode = "whole"
+	// ModeSubstring matches the word anywhere, including inside other
+	// words.
+	ModeSubstring Mode = "substring"
+)
+
+// Strategy controls what a match is replaced with.
+type Strategy string
+
+const (
+	// StrategyMask replaces the match with a fixed "****".
+	StrategyMask Strategy = "mask"
+	// StrategyStars replaces each letter of the match with "*".
+	StrategyStars Strategy = "stars"
+	// StrategyReplace replaces the match with the rule's Replacement.
+	StrategyReplace Strategy = "replace"
+)
+
+const mask = "****"
+
+// Rule is one entry of the word list.
+type Rule struct {
+	Word        string
+	Mode        Mode
+	Strategy    Strategy
+	Replacement string
+}
+
+// ParseMode accepts the word list spelling of a Mode. An empty string
+// means ModeWholeWord.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "", ModeWholeWord:
+		return ModeWholeWord, nil
+	case ModeSubstring:
+		return ModeSubstring, nil
+	}
+
+	return "", fmt.Errorf("unknown match mode %q", s)
+}
+
+// ParseStrategy accepts the word list spelling of a Strategy. An empty
+// string means StrategyMask.
+func ParseStrategy(s string) (Strategy, error) {
+	switch Strategy(s) {
+	case "", StrategyMask:
+		return StrategyMask, nil
+	case StrategyStars:
+		return StrategyStars, nil
+	case StrategyReplace:
+		return StrategyReplace, nil
+	}
+
+	return "", fmt.Errorf("unknown replacement strategy %q", s)
+}
+
+type compiledRule struct {
+	Rule
+	pattern []rune
+}
+
+// Filter is an immutable, compiled word list. The zero value and a nil
+// *Filter both leave text unchanged.
+type Filter struct {
+	rules []compiledRule
+}
+
+// New compiles rules into a Filter. Words are normalized the same way as
+// the text they are matched against.
+func New(rules []Rule) (*Filter, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		pattern := normalizeWord(rule.Word)
+		if len(pattern) == 0 {
+			return nil, fmt.Errorf("word %q has no letters to match", rule.Word)
+		}
+		mode, err := ParseMode(string(rule.Mode))
+		if err != nil {
+			return nil, fmt.Errorf("word %q: %w", rule.Word, err)
+		}
+		strategy, err := ParseStrategy(string(rule.Strategy))
+		if err != nil {
+			return nil, fmt.Errorf("word %q: %w", rule.Word, err)
+		}
+		rule.Mode = mode
+		rule.Strategy = strategy
+		compiled = append(compiled, compiledRule{Rule: rule, pattern: pattern})
+	}
+
+	// Longest first, so "fornaxes" wins over "fornax" at the same position.
+	sort.SliceStable(compiled, func(i, j int) bool {
+		return len(compiled[i].pattern) > len(compiled[j].pattern)
+	})
+
+	return &Filter{rules: compiled}, nil
+}
+
+// textRune is one normalized word rune along with where it came from.
+type textRune struct {
+	r          rune
+	start, end int
+	// wordStart is set when a separator (or the start of the text)
+	// comes right before this rune, spaceBefore when that separator
+	// included whitespace.
+	wordStart   bool
+	spaceBefore bool
+}
+
+// Clean returns text with every blocked word replaced. Matching ignores
+// case, homoglyphs, leetspeak, and punctuation or zero-width characters
+// inside a word, so "K.3.r-f.u.f.f.l.e" does not get through. A match never
+// spans whitespace.
+func (f *Filter) Clean(text string) string {
+	if f == nil || len(f.rules) == 0 {
+		return text
+	}
+
+	runes := scan(text)
+	var b strings.Builder
+	copied := 0
+	for i := 0; i < len(runes); {
+		rule, ok := f.match(runes, i)
+		if !ok {
+			i++
+			continue
+		}
+
+		end := i + len(rule.pattern)
+		b.WriteString(text[copied:runes[i].start])
+		b.WriteString(replacement(rule))
+		copied = runes[end-1].end
+		i = end
+	}
+	if copied == 0 {
+		return text
+	}
+	b.WriteString(text[copied:])
+
+	return b.String()
+}
+
+func (f *Filter) match(runes []textRune, i int) (compiledRule, bool) {
+	for _, rule := range f.rules {
+		end := i + len(rule.pattern)
+		if end > len(runes) {
+			continue
+		}
+		if rule.Mode == ModeWholeWord {
+			if !runes[i].wordStart || (end < len(runes) && !runes[end].wordStart) {
+				continue
+			}
+		}
+
+		matched := true
+		for j, r := range rule.pattern {
+			if runes[i+j].r != r || (j > 0 && runes[i+j].spaceBefore) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return rule, true
+		}
+	}
+
+	return compiledRule{}, false
+}
+
+// scan returns the normalized word runes of text. Separators are dropped
+// rather than kept, which is what lets "k.e.r.f" match as one word; the
+// flags on the following rune remember that they were there.
+func scan(text string) []textRune {
+	var runes []textRune
+	separated, spaced := true, true
+	leet, tokenEnd := false, 0
+	for offset, r := range text {
+		// Leetspeak only applies within whitespace-delimited tokens that
+		// also contain letters.
+		if offset >= tokenEnd && !unicode.IsSpace(r) {
+			end := strings.IndexFunc(text[offset:], unicode.IsSpace)
+			if end < 0 {
+				end = len(text) - offset
+			}
+			tokenEnd = offset + end
+			leet = hasLetter(text[offset:tokenEnd])
+		}
+
+		folded, class := normalizeRune(r, leet)
+		switch class {
+		case classIgnorable:
+			// Keep combining marks with the letter they decorate so a
+			// replacement does not leave them dangling.
+			if len(runes) > 0 && !separated {
+				runes[len(runes)-1].end = offset + utf8.RuneLen(r)
+			}
+			continue
+		case classSeparator:
+			separated = true
+			spaced = spaced || unicode.IsSpace(r)
+			continue
+		}
+
+		runes = append(runes, textRune{
+			r:           folded,
+			start:       offset,
+			end:         offset + utf8.RuneLen(r),
+			wordStart:   separated,
+			spaceBefore: spaced,
+		})
+		separated, spaced = false, false
+	}
+
+	return runes
+}
+
+func replacement(rule compiledRule) string {
+	switch rule.Strategy {
+	case StrategyStars:
+		return strings.Repeat("*", len(rule.pattern))
+	case StrategyReplace:
+		return rule.Replacement
+	}
+
+	return mask
+}
@@ -0,0 +1,188 @@
+package moderation
+
+import "testing"
+
+func TestClean(t *testing.T) {
+	type Case struct {
+		name  string
+		rules []Rule
+		input string
+		want  string
+	}
+
+	defaults := []Rule{
+		{Word: "kerfuffle"},
+		{Word: "sharbert"},
+		{Word: "fornax"},
+	}
+
+	cases := []Case{
+		{
+			name:  "Clean text is unchanged",
+			rules: defaults,
+			input: "I had something interesting for breakfast",
+			want:  "I had something interesting for breakfast",
+		},
+		{
+			name:  "Case insensitive",
+			rules: defaults,
+			input: "This is a Kerfuffle opinion I need to share with the world",
+			want:  "This is a **** opinion I need to share with the world",
+		},
+		{
+			name:  "Trailing punctuation",
+			rules: defaults,
+			input: "What a kerfuffle! Sharbert, fornax.",
+			want:  "What a ****! ****, ****.",
+		},
+		{
+			name:  "Punctuation inside a word",
+			rules: defaults,
+			input: "k.e.r.f-u_f.f.l.e",
+			want:  "****",
+		},
+		{
+			name:  "Zero-width characters inside a word",
+			rules: defaults,
+			input: "for​nax",
+			want:  "****",
+		},
+		{
+			name:  "Leetspeak",
+			rules: defaults,
+			input: "f0rn4x and $h@rb3rt",
+			want:  "**** and ****",
+		},
+		{
+			name:  "Plain numbers are not leetspeak",
+			rules: []Rule{{Word: "ass"}, {Word: "sebs", Mode: ModeSubstring}},
+			input: "call 455 or 5385, $455",
+			want:  "call 455 or 5385, $455",
+		},
+		{
+			name:  "Leetspeak digits next to letters",
+			rules: []Rule{{Word: "ass"}},
+			input: "a55 and 4ss",
+			want:  "**** and ****",
+		},
+		{
+			name:  "Homoglyphs and diacritics",
+			rules: defaults,
+			input: "fоrnах kérfüfflé",
+			want:  "**** ****",
+		},
+		{
+			name:  "Combining marks",
+			rules: defaults,
+			input: "fornax́",
+			want:  "****",
+		},
+		{
+			name:  "Unicode case folding",
+			rules: []Rule{{Word: "kiss"}},
+			input: "KISS ſuch",
+			want:  "**** ſuch",
+		},
+		{
+			name:  "Fullwidth letters",
+			rules: defaults,
+			input: "ＦＯＲＮＡＸ",
+			want:  "****",
+		},
+		{
+			name:  "Whole word does not match inside words",
+			rules: []Rule{{Word: "ass", Mode: ModeWholeWord}},
+			input: "a classic ass",
+			want:  "a classic ****",
+		},
+		{
+			name:  "Substring matches inside words",
+			rules: []Rule{{Word: "fornax", Mode: ModeSubstring}},
+			input: "Fornaxes everywhere",
+			want:  "****es everywhere",
+		},
+		{
+			name:  "Matches do not span whitespace",
+			rules: []Rule{{Word: "ass"}},
+			input: "a ss",
+			want:  "a ss",
+		},
+		{
+			name:  "Stars strategy",
+			rules: []Rule{{Word: "fornax", Strategy: StrategyStars}},
+			input: "fornax!",
+			want:  "******!",
+		},
+		{
+			name:  "Replace strategy",
+			rules: []Rule{{Word: "kerfuffle", Strategy: StrategyReplace, Replacement: "commotion"}},
+			input: "quite the kerfuffle",
+			want:  "quite the commotion",
+		},
+		{
+			name:  "Longest word wins",
+			rules: []Rule{{Word: "forn", Mode: ModeSubstring}, {Word: "fornax", Mode: ModeSubstring, Strategy: StrategyStars}},
+			input: "fornax",
+			want:  "******",
+		},
+		{
+			name:  "No rules",
+			rules: nil,
+			input: "kerfuffle",
+			want:  "kerfuffle",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			filter, err := New(c.rules)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			got := filter.Clean(c.input)
+			if got != c.want {
+				t.Errorf("Clean(%q) = %q, want %q", c.input, got, c.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	type Case struct {
+		name    string
+		rule    Rule
+		wantErr bool
+	}
+
+	cases := []Case{
+		{
+			name:    "Defaults",
+			rule:    Rule{Word: "fornax"},
+			wantErr: false,
+		},
+		{
+			name:    "No letters",
+			rule:    Rule{Word: "!!"},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown mode",
+			rule:    Rule{Word: "fornax", Mode: "prefix"},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown strategy",
+			rule:    Rule{Word: "fornax", Strategy: "blur"},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := New([]Rule{c.rule})
+			if (err != nil) != c.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
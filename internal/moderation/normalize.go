@@ -0,0 +1,119 @@
+package moderation
+
+import (
+	"strings"
+	"unicode"
+)
+
+// homoglyphs maps letters that render like ASCII letters onto them, so
+// Cyrillic "а" or an accented "é" cannot be swapped in to dodge a match.
+var homoglyphs = map[rune]rune{
+	// Latin letters with diacritics.
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ā': 'a', 'ă': 'a', 'ą': 'a',
+	'ç': 'c', 'ć': 'c', 'č': 'c',
+	'ď': 'd', 'đ': 'd',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ē': 'e', 'ė': 'e', 'ę': 'e', 'ě': 'e',
+	'ğ': 'g',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i', 'ī': 'i', 'į': 'i', 'ı': 'i',
+	'ł': 'l', 'ľ': 'l',
+	'ñ': 'n', 'ń': 'n', 'ň': 'n',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o', 'ō': 'o', 'ő': 'o',
+	'ř': 'r',
+	'ś': 's', 'š': 's', 'ş': 's',
+	'ť': 't', 'ţ': 't',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ū': 'u', 'ů': 'u', 'ű': 'u',
+	'ý': 'y', 'ÿ': 'y',
+	'ź': 'z', 'ż': 'z', 'ž': 'z',
+	// Cyrillic.
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'і': 'i', 'ї': 'i', 'ј': 'j', 'к': 'k',
+	'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x',
+	'ѕ': 's', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w',
+	// Greek.
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o',
+	'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x',
+}
+
+// leetspeak maps digits and symbols commonly typed in place of letters.
+var leetspeak = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'8': 'b',
+	'9': 'g',
+	'@': 'a',
+	'$': 's',
+}
+
+type runeClass int
+
+const (
+	// classWord runes are compared against the word list.
+	classWord runeClass = iota
+	// classSeparator runes (spaces, punctuation) end a word.
+	classSeparator
+	// classIgnorable runes (zero-width and other format characters) are
+	// skipped as if they were not there.
+	classIgnorable
+)
+
+// normalizeRune folds r to the lowercase ASCII-ish letter it stands for and
+// reports how the filter should treat it. Leetspeak is only applied when
+// leet is set, so plain numbers like "455" keep their digits.
+func normalizeRune(r rune, leet bool) (rune, runeClass) {
+	if folded, ok := leetspeak[r]; ok && leet {
+		return folded, classWord
+	}
+	if unicode.Is(unicode.Cf, r) || unicode.Is(unicode.Mn, r) {
+		return r, classIgnorable
+	}
+	if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+		return r, classSeparator
+	}
+
+	r = foldCase(r)
+	if r >= 'ａ' && r <= 'ｚ' {
+		r = 'a' + (r - 'ａ')
+	}
+	if folded, ok := homoglyphs[r]; ok {
+		r = folded
+	}
+
+	return r, classWord
+}
+
+// foldCase maps every rune in a case-folding orbit (e.g. "K", "k" and the
+// Kelvin sign) to the same lowercase rune.
+func foldCase(r rune) rune {
+	lowest := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < lowest {
+			lowest = f
+		}
+	}
+
+	return unicode.ToLower(lowest)
+}
+
+// hasLetter reports whether s contains a letter, which is what marks a
+// token as one that may be written in leetspeak.
+func hasLetter(s string) bool {
+	return strings.IndexFunc(s, unicode.IsLetter) >= 0
+}
+
+// normalizeWord returns the normalized form of a word list entry, dropping
+// anything that is not a word rune.
+func normalizeWord(word string) []rune {
+	leet := hasLetter(word)
+	var out []rune
+	for _, r := range word {
+		folded, class := normalizeRune(r, leet)
+		if class == classWord {
+			out = append(out, folded)
+		}
+	}
+
+	return out
+}
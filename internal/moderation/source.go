@@ -0,0 +1,125 @@
+package moderation
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Source loads the current word list.
+type Source interface {
+	Load(ctx context.Context) ([]Rule, error)
+}
+
+// SourceFunc adapts a function to a Source.
+type SourceFunc func(ctx context.Context) ([]Rule, error)
+
+func (f SourceFunc) Load(ctx context.Context) ([]Rule, error) {
+	return f(ctx)
+}
+
+// FileSource reads a word list file on every Load, so edits to the file
+// are picked up by the next reload.
+type FileSource struct {
+	Path string
+}
+
+func (s FileSource) Load(ctx context.Context) ([]Rule, error) {
+	file, err := os.Open(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseWordList(file)
+}
+
+// ParseWordList reads one rule per line:
+//
+//	word [mode] [strategy [replacement...]]
+//
+// where mode is "whole" or "substring" and strategy is "mask", "stars" or
+// "replace". Blank lines and lines starting with # are skipped.
+func ParseWordList(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		rule := Rule{Word: fields[0]}
+		var err error
+		if len(fields) > 1 {
+			rule.Mode, err = ParseMode(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+		}
+		if len(fields) > 2 {
+			rule.Strategy, err = ParseStrategy(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+		}
+		if len(fields) > 3 {
+			if rule.Strategy != StrategyReplace {
+				return nil, fmt.Errorf("line %d: only the replace strategy takes a replacement", lineNumber)
+			}
+			rule.Replacement = strings.Join(fields[3:], " ")
+		}
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// Live holds the Filter built from a Source and swaps in a new one on
+// Reload. It is safe for concurrent use.
+type Live struct {
+	source  Source
+	current atomic.Pointer[Filter]
+}
+
+// NewLive loads source once and fails if that first load does.
+func NewLive(ctx context.Context, source Source) (*Live, error) {
+	live := &Live{source: source}
+	err := live.Reload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return live, nil
+}
+
+// Reload rebuilds the filter from the source. On error the previous
+// filter stays in place.
+func (l *Live) Reload(ctx context.Context) error {
+	rules, err := l.source.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("loading word list: %w", err)
+	}
+	filter, err := New(rules)
+	if err != nil {
+		return fmt.Errorf("compiling word list: %w", err)
+	}
+	l.current.Store(filter)
+
+	return nil
+}
+
+// Clean runs text through the current filter.
+func (l *Live) Clean(text string) string {
+	return l.current.Load().Clean(text)
+}
@@ -0,0 +1,104 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseWordList(t *testing.T) {
+	type Case struct {
+		name    string
+		input   string
+		want    []Rule
+		wantErr bool
+	}
+
+	cases := []Case{
+		{
+			name:  "Words with options",
+			input: "# blocked words\nkerfuffle\n\nfornax substring stars\nsharbert whole replace nice try\n",
+			want: []Rule{
+				{Word: "kerfuffle"},
+				{Word: "fornax", Mode: ModeSubstring, Strategy: StrategyStars},
+				{Word: "sharbert", Mode: ModeWholeWord, Strategy: StrategyReplace, Replacement: "nice try"},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Unknown mode",
+			input:   "fornax everywhere",
+			wantErr: true,
+		},
+		{
+			name:    "Replacement without replace strategy",
+			input:   "fornax whole mask oops",
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ParseWordList(strings.NewReader(c.input))
+			if (err != nil) != c.wantErr {
+				t.Errorf("ParseWordList() error = %v, wantErr %v", err, c.wantErr)
+				return
+			}
+			if !c.wantErr && !reflect.DeepEqual(got, c.want) {
+				t.Errorf("ParseWordList() got = %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestLiveReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	err := os.WriteFile(path, []byte("kerfuffle\n"), 0o644)
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	live, err := NewLive(context.Background(), FileSource{Path: path})
+	if err != nil {
+		t.Fatalf("NewLive() error = %v", err)
+	}
+	if got := live.Clean("kerfuffle fornax"); got != "**** fornax" {
+		t.Errorf("Clean() = %q, want %q", got, "**** fornax")
+	}
+
+	err = os.WriteFile(path, []byte("fornax\n"), 0o644)
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	err = live.Reload(context.Background())
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := live.Clean("kerfuffle fornax"); got != "kerfuffle ****" {
+		t.Errorf("Clean() after reload = %q, want %q", got, "kerfuffle ****")
+	}
+
+	err = os.WriteFile(path, []byte("fornax sometimes\n"), 0o644)
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	err = live.Reload(context.Background())
+	if err == nil {
+		t.Errorf("Reload() of a bad word list error = nil, want error")
+	}
+	if got := live.Clean("kerfuffle fornax"); got != "kerfuffle ****" {
+		t.Errorf("Clean() after failed reload = %q, want %q", got, "kerfuffle ****")
+	}
+
+	failing := SourceFunc(func(ctx context.Context) ([]Rule, error) {
+		return nil, errors.New("database is down")
+	})
+	_, err = NewLive(context.Background(), failing)
+	if err == nil {
+		t.Errorf("NewLive() with failing source error = nil, want error")
+	}
+}
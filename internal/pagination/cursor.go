@@ -0,0 +1,98 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+
+	// MaxOffset bounds how deep offset pagination can go, since the
+	// database still sorts every skipped row.
+	MaxOffset = 1000
+)
+
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+func EncodeCursor(cursor Cursor) string {
+	raw := fmt.Sprintf("%s|%s", cursor.CreatedAt.UTC().Format(time.RFC3339Nano), cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(encoded string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("Error decoding cursor: %w", err)
+	}
+
+	createdAtString, idString, found := strings.Cut(string(data), "|")
+	if !found {
+		return Cursor{}, errors.New("malformed cursor")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtString)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("Error parsing cursor time: %w", err)
+	}
+
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("Error parsing cursor id: %w", err)
+	}
+
+	return Cursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+func ParseLimit(raw string) (int32, error) {
+	if raw == "" {
+		return DefaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("Error parsing limit: %w", err)
+	}
+
+	if limit < 1 || limit > MaxLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", MaxLimit)
+	}
+
+	return int32(limit), nil
+}
+
+func EncodeOffsetCursor(offset int32) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset|" + strconv.Itoa(int(offset))))
+}
+
+func DecodeOffsetCursor(encoded string) (int32, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, fmt.Errorf("Error decoding cursor: %w", err)
+	}
+
+	offsetString, found := strings.CutPrefix(string(data), "offset|")
+	if !found {
+		return 0, errors.New("malformed cursor")
+	}
+
+	offset, err := strconv.ParseInt(offsetString, 10, 32)
+	if err != nil || offset < 0 {
+		return 0, errors.New("malformed cursor offset")
+	}
+	if offset > MaxOffset {
+		return 0, fmt.Errorf("cursor offset must be at most %d", MaxOffset)
+	}
+
+	return int32(offset), nil
+}
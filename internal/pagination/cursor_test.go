@@ -0,0 +1,117 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{
+		CreatedAt: time.Date(2025, 3, 14, 15, 9, 26, 535897000, time.UTC),
+		ID:        uuid.New(),
+	}
+
+	got, err := DecodeCursor(EncodeCursor(cursor))
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+
+	if !got.CreatedAt.Equal(cursor.CreatedAt) || got.ID != cursor.ID {
+		t.Errorf("DecodeCursor() got = %v, want %v", got, cursor)
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	type Case struct {
+		name    string
+		cursor  string
+		wantErr bool
+	}
+
+	cases := []Case{
+		{
+			name:    "Valid cursor",
+			cursor:  EncodeCursor(Cursor{CreatedAt: time.Now(), ID: uuid.New()}),
+			wantErr: false,
+		},
+		{
+			name:    "Not base64",
+			cursor:  "not a cursor!",
+			wantErr: true,
+		},
+		{
+			name:    "Missing separator",
+			cursor:  "MjAyNS0wMS0wMVQwMDowMDowMFo",
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := DecodeCursor(c.cursor)
+			if (err != nil) != c.wantErr {
+				t.Errorf("DecodeCursor() error = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	type Case struct {
+		name      string
+		raw       string
+		wantLimit int32
+		wantErr   bool
+	}
+
+	cases := []Case{
+		{name: "Default", raw: "", wantLimit: DefaultLimit, wantErr: false},
+		{name: "Valid", raw: "50", wantLimit: 50, wantErr: false},
+		{name: "Too large", raw: "101", wantLimit: 0, wantErr: true},
+		{name: "Zero", raw: "0", wantLimit: 0, wantErr: true},
+		{name: "Not a number", raw: "ten", wantLimit: 0, wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			limit, err := ParseLimit(c.raw)
+			if (err != nil) != c.wantErr {
+				t.Errorf("ParseLimit() error = %v, wantErr %v", err, c.wantErr)
+			}
+			if limit != c.wantLimit {
+				t.Errorf("ParseLimit() got = %v, want %v", limit, c.wantLimit)
+			}
+		})
+	}
+}
+
+func TestDecodeOffsetCursor(t *testing.T) {
+	type Case struct {
+		name       string
+		cursor     string
+		wantOffset int32
+		wantErr    bool
+	}
+
+	cases := []Case{
+		{name: "Valid cursor", cursor: EncodeOffsetCursor(40), wantOffset: 40, wantErr: false},
+		{name: "Time cursor", cursor: EncodeCursor(Cursor{CreatedAt: time.Now(), ID: uuid.New()}), wantOffset: 0, wantErr: true},
+		{name: "Negative offset", cursor: "b2Zmc2V0fC0x", wantOffset: 0, wantErr: true},
+		{name: "Deepest offset", cursor: EncodeOffsetCursor(MaxOffset), wantOffset: MaxOffset, wantErr: false},
+		{name: "Offset past the maximum", cursor: EncodeOffsetCursor(MaxOffset + 1), wantOffset: 0, wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			offset, err := DecodeOffsetCursor(c.cursor)
+			if (err != nil) != c.wantErr {
+				t.Errorf("DecodeOffsetCursor() error = %v, wantErr %v", err, c.wantErr)
+			}
+			if offset != c.wantOffset {
+				t.Errorf("DecodeOffsetCursor() got = %v, want %v", offset, c.wantOffset)
+			}
+		})
+	}
+}
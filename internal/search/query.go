@@ -0,0 +1,102 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// BuildTSQuery turns user search input into to_tsquery syntax. Quoted
+// text becomes a phrase, a trailing * a prefix match, a leading - a
+// negation, and OR joins the terms on either side of it. Everything
+// other than letters and digits is dropped so the result always parses.
+func BuildTSQuery(input string) (string, error) {
+	var clauses []string
+	joinWithOr := false
+
+	appendClause := func(clause string) {
+		if clause == "" {
+			return
+		}
+		if len(clauses) > 0 {
+			if joinWithOr {
+				clauses = append(clauses, "|")
+			} else {
+				clauses = append(clauses, "&")
+			}
+		}
+		clauses = append(clauses, clause)
+		joinWithOr = false
+	}
+
+	rest := strings.TrimSpace(input)
+	for rest != "" {
+		if strings.HasPrefix(rest, `"`) {
+			phrase, after, found := strings.Cut(rest[1:], `"`)
+			if !found {
+				after = ""
+			}
+			appendClause(phraseClause(phrase))
+			rest = strings.TrimSpace(after)
+			continue
+		}
+
+		token, after := rest, ""
+		if i := strings.IndexFunc(rest, unicode.IsSpace); i >= 0 {
+			token, after = rest[:i], rest[i:]
+		}
+		rest = strings.TrimSpace(after)
+
+		if token == "OR" {
+			joinWithOr = len(clauses) > 0
+			continue
+		}
+
+		negate := strings.HasPrefix(token, "-")
+		prefix := strings.HasSuffix(token, "*")
+		term := sanitizeTerm(token)
+		if term == "" {
+			continue
+		}
+		if prefix {
+			term += ":*"
+		}
+		if negate {
+			term = "!" + term
+		}
+		appendClause(term)
+	}
+
+	if len(clauses) == 0 {
+		return "", errors.New("search query has no searchable terms")
+	}
+
+	return strings.Join(clauses, " "), nil
+}
+
+func phraseClause(phrase string) string {
+	var terms []string
+	for _, word := range strings.Fields(phrase) {
+		if term := sanitizeTerm(word); term != "" {
+			terms = append(terms, term)
+		}
+	}
+
+	switch len(terms) {
+	case 0:
+		return ""
+	case 1:
+		return terms[0]
+	default:
+		return "(" + strings.Join(terms, " <-> ") + ")"
+	}
+}
+
+func sanitizeTerm(token string) string {
+	return strings.ToLower(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, token))
+}
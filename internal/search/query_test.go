@@ -0,0 +1,94 @@
+package search
+
+import "testing"
+
+func TestBuildTSQuery(t *testing.T) {
+	type Case struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}
+
+	cases := []Case{
+		{
+			name:    "Single word",
+			input:   "chirpy",
+			want:    "chirpy",
+			wantErr: false,
+		},
+		{
+			name:    "Words are ANDed",
+			input:   "golang  chirps",
+			want:    "golang & chirps",
+			wantErr: false,
+		},
+		{
+			name:    "Phrase",
+			input:   `"hello big world"`,
+			want:    "(hello <-> big <-> world)",
+			wantErr: false,
+		},
+		{
+			name:    "Prefix",
+			input:   "chir*",
+			want:    "chir:*",
+			wantErr: false,
+		},
+		{
+			name:    "Negation",
+			input:   "boots -cats",
+			want:    "boots & !cats",
+			wantErr: false,
+		},
+		{
+			name:    "Tabs and newlines separate words",
+			input:   "foo\tbar\nbaz\r\n-qux",
+			want:    "foo & bar & baz & !qux",
+			wantErr: false,
+		},
+		{
+			name:    "OR",
+			input:   "cats OR dogs fish",
+			want:    "cats | dogs & fish",
+			wantErr: false,
+		},
+		{
+			name:    "Operators are stripped",
+			input:   "a&b | c:*!",
+			want:    "ab & c",
+			wantErr: false,
+		},
+		{
+			name:    "Unicode is kept and lowercased",
+			input:   "Ünïcode",
+			want:    "ünïcode",
+			wantErr: false,
+		},
+		{
+			name:    "Unterminated phrase",
+			input:   `"open phrase`,
+			want:    "(open <-> phrase)",
+			wantErr: false,
+		},
+		{
+			name:    "Nothing searchable",
+			input:   `!!! "" OR`,
+			want:    "",
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := BuildTSQuery(c.input)
+			if (err != nil) != c.wantErr {
+				t.Errorf("BuildTSQuery() error = %v, wantErr %v", err, c.wantErr)
+				return
+			}
+			if got != c.want {
+				t.Errorf("BuildTSQuery() got = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
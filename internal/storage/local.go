@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local stores blobs under Root and serves them from BaseURL, which the
+// server maps onto Root with a file server.
+type Local struct {
+	Root    string
+	BaseURL string
+}
+
+func NewLocal(root, baseURL string) (*Local, error) {
+	err := os.MkdirAll(root, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating storage root: %w", err)
+	}
+
+	return &Local{
+		Root:    root,
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+// Put writes to a temporary file first so readers never see a partial blob.
+func (l *Local) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	err := ValidateKey(key)
+	if err != nil {
+		return err
+	}
+
+	dest := filepath.Join(l.Root, filepath.FromSlash(key))
+	err = os.MkdirAll(filepath.Dir(dest), 0o755)
+	if err != nil {
+		return fmt.Errorf("Error creating directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("Error creating temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("Error writing blob: %w", err)
+	}
+
+	err = os.Rename(tmp.Name(), dest)
+	if err != nil {
+		return fmt.Errorf("Error moving blob into place: %w", err)
+	}
+
+	return nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	err := ValidateKey(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filepath.Join(l.Root, filepath.FromSlash(key)))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("Error deleting blob: %w", err)
+	}
+
+	return nil
+}
+
+func (l *Local) URL(key string) string {
+	return l.BaseURL + "/" + key
+}
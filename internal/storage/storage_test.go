@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateKey(t *testing.T) {
+	type Case struct {
+		name    string
+		key     string
+		wantErr bool
+	}
+
+	cases := []Case{
+		{name: "Nested key", key: "media/abc/def.png", wantErr: false},
+		{name: "Empty", key: "", wantErr: true},
+		{name: "Absolute", key: "/etc/passwd", wantErr: true},
+		{name: "Parent directory", key: "media/../../etc/passwd", wantErr: true},
+		{name: "Backslash", key: "media\\file.png", wantErr: true},
+		{name: "Double slash", key: "media//file.png", wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateKey(c.key)
+			if (err != nil) != c.wantErr {
+				t.Errorf("ValidateKey(%q) error = %v, wantErr %v", c.key, err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestLocalPutDelete(t *testing.T) {
+	root := t.TempDir()
+	l, err := NewLocal(root, "/media/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = l.Put(context.Background(), "a/b.png", strings.NewReader("blob"), "image/png")
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "a", "b.png"))
+	if err != nil || string(data) != "blob" {
+		t.Errorf("stored file = %q, %v, want %q", data, err, "blob")
+	}
+	if got := l.URL("a/b.png"); got != "/media/a/b.png" {
+		t.Errorf("URL() = %v, want /media/a/b.png", got)
+	}
+
+	err = l.Delete(context.Background(), "a/b.png")
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	err = l.Delete(context.Background(), "a/b.png")
+	if err != nil {
+		t.Errorf("Delete() of missing blob error = %v, want nil", err)
+	}
+
+	err = l.Put(context.Background(), "../escape.png", strings.NewReader("blob"), "image/png")
+	if err == nil {
+		t.Errorf("Put() outside root error = nil, want error")
+	}
+}
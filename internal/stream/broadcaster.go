@@ -0,0 +1,179 @@
+package stream
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Event is a single message delivered to stream subscribers. IDs increase
+// monotonically, across restarts too, so clients can resume with
+// Last-Event-ID. Topics such as "user:<id>" or "hashtag:<tag>" let
+// subscribers filter what they receive.
+type Event struct {
+	ID     uint64
+	Type   string
+	Topics []string
+	Data   []byte
+}
+
+func (e Event) HasTopic(topic string) bool {
+	return slices.Contains(e.Topics, topic)
+}
+
+// Broadcaster fans events out to subscribers. The in-process Memory
+// implementation can be replaced by one backed by Postgres LISTEN/NOTIFY
+// without touching the handlers.
+type Broadcaster interface {
+	Publish(eventType string, topics []string, data []byte) Event
+	Subscribe(lastEventID uint64, topics ...string) (*Subscription, []Event, bool)
+	Unsubscribe(sub *Subscription)
+	LastID() uint64
+}
+
+// Subscription receives events on C. C is closed when the subscriber is
+// unsubscribed or falls too far behind to keep up.
+type Subscription struct {
+	C <-chan Event
+
+	ch     chan Event
+	topics []string
+	closed bool
+}
+
+// wants reports whether the subscription asked for event. Subscriptions
+// without topics receive everything.
+func (s *Subscription) wants(event Event) bool {
+	if len(s.topics) == 0 {
+		return true
+	}
+	for _, topic := range s.topics {
+		if event.HasTopic(topic) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type Memory struct {
+	mu          sync.Mutex
+	firstID     uint64
+	nextID      uint64
+	history     []Event
+	historySize int
+	bufferSize  int
+	subscribers map[*Subscription]struct{}
+}
+
+// NewMemory returns a broadcaster that keeps the last historySize events
+// for replay and buffers up to bufferSize events per subscriber.
+func NewMemory(historySize, bufferSize int) *Memory {
+	return newMemory(historySize, bufferSize, time.Now())
+}
+
+// newMemory numbers events from the boot time in microseconds, so IDs keep
+// increasing across restarts and an ID from an earlier process is
+// recognisably older than anything this one has published.
+func newMemory(historySize, bufferSize int, boot time.Time) *Memory {
+	firstID := uint64(boot.UnixMicro())
+	return &Memory{
+		firstID:     firstID,
+		nextID:      firstID,
+		historySize: historySize,
+		bufferSize:  bufferSize,
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+func (m *Memory) Publish(eventType string, topics []string, data []byte) Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event := Event{
+		ID:     m.nextID,
+		Type:   eventType,
+		Topics: topics,
+		Data:   data,
+	}
+	m.nextID++
+
+	m.history = append(m.history, event)
+	if len(m.history) > m.historySize {
+		m.history = m.history[len(m.history)-m.historySize:]
+	}
+
+	for sub := range m.subscribers {
+		if !sub.wants(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			// Slow subscribers are dropped rather than blocking publishers;
+			// they can reconnect and resume from their last event ID.
+			m.remove(sub)
+		}
+	}
+
+	return event
+}
+
+// Subscribe registers a new subscriber for events on any of topics, or on
+// every topic if none are given, and returns the retained matching events
+// newer than lastEventID. A lastEventID of zero skips replay. The boolean is
+// false when events after lastEventID may be missing from the replay,
+// because they were dropped from history or came from an earlier process,
+// so the client must reload instead of resuming.
+func (m *Memory) Subscribe(lastEventID uint64, topics ...string) (*Subscription, []Event, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ch := make(chan Event, m.bufferSize)
+	sub := &Subscription{C: ch, ch: ch, topics: topics}
+	m.subscribers[sub] = struct{}{}
+
+	replay := []Event{}
+	if lastEventID == 0 {
+		return sub, replay, true
+	}
+
+	oldestID := m.nextID
+	if len(m.history) > 0 {
+		oldestID = m.history[0].ID
+	}
+	complete := lastEventID >= m.firstID && lastEventID+1 >= oldestID && lastEventID < m.nextID
+
+	for _, event := range m.history {
+		if event.ID > lastEventID && sub.wants(event) {
+			replay = append(replay, event)
+		}
+	}
+
+	return sub, replay, complete
+}
+
+// LastID returns the ID of the most recently published event, which
+// clients told to reload can resume from.
+func (m *Memory) LastID() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.nextID - 1
+}
+
+func (m *Memory) Unsubscribe(sub *Subscription) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(sub)
+}
+
+func (m *Memory) remove(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(m.subscribers, sub)
+	close(sub.ch)
+}
@@ -0,0 +1,171 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMemoryPublish(t *testing.T) {
+	m := NewMemory(10, 10)
+	sub, replay, ok := m.Subscribe(0)
+	if len(replay) != 0 || !ok {
+		t.Fatalf("Subscribe() replay = %v, %v, want none", replay, ok)
+	}
+
+	topic := "user:" + uuid.NewString()
+	published := m.Publish("chirp", []string{topic}, []byte("{}"))
+
+	got := <-sub.C
+	if got.ID != published.ID || got.Type != "chirp" || !got.HasTopic(topic) {
+		t.Errorf("received %v, want %v", got, published)
+	}
+	if m.LastID() != published.ID {
+		t.Errorf("LastID() = %d, want %d", m.LastID(), published.ID)
+	}
+
+	m.Unsubscribe(sub)
+	if _, ok := <-sub.C; ok {
+		t.Errorf("channel still open after Unsubscribe()")
+	}
+	m.Unsubscribe(sub)
+}
+
+func TestMemoryReplay(t *testing.T) {
+	boot := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := uint64(boot.UnixMicro())
+
+	type Case struct {
+		name        string
+		lastEventID uint64
+		topics      []string
+		wantIDs     []uint64
+		wantOK      bool
+	}
+
+	// Five events are published and the last three retained.
+	cases := []Case{
+		{
+			name:        "No last event ID",
+			lastEventID: 0,
+			wantIDs:     []uint64{},
+			wantOK:      true,
+		},
+		{
+			name:        "Resume mid history",
+			lastEventID: first + 3,
+			wantIDs:     []uint64{first + 4},
+			wantOK:      true,
+		},
+		{
+			name:        "Resume just before retained history",
+			lastEventID: first + 1,
+			wantIDs:     []uint64{first + 2, first + 3, first + 4},
+			wantOK:      true,
+		},
+		{
+			name:        "Older than retained history",
+			lastEventID: first,
+			wantIDs:     []uint64{first + 2, first + 3, first + 4},
+			wantOK:      false,
+		},
+		{
+			name:        "From an earlier process",
+			lastEventID: 5000,
+			wantIDs:     []uint64{first + 2, first + 3, first + 4},
+			wantOK:      false,
+		},
+		{
+			name:        "From the future",
+			lastEventID: first + 100,
+			wantIDs:     []uint64{},
+			wantOK:      false,
+		},
+		{
+			name:        "Up to date",
+			lastEventID: first + 4,
+			wantIDs:     []uint64{},
+			wantOK:      true,
+		},
+		{
+			name:        "Replay is filtered by topic",
+			lastEventID: first + 1,
+			topics:      []string{"odd"},
+			wantIDs:     []uint64{first + 3},
+			wantOK:      true,
+		},
+	}
+
+	m := newMemory(3, 10, boot)
+	for i := 0; i < 5; i++ {
+		topic := "even"
+		if i%2 == 1 {
+			topic = "odd"
+		}
+		m.Publish("chirp", []string{topic}, nil)
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sub, replay, ok := m.Subscribe(c.lastEventID, c.topics...)
+			defer m.Unsubscribe(sub)
+
+			if ok != c.wantOK {
+				t.Errorf("Subscribe() ok = %v, want %v", ok, c.wantOK)
+			}
+			if len(replay) != len(c.wantIDs) {
+				t.Fatalf("Subscribe() replay = %v, want IDs %v", replay, c.wantIDs)
+			}
+			for i, event := range replay {
+				if event.ID != c.wantIDs[i] {
+					t.Errorf("Subscribe() replay[%d].ID = %d, want %d", i, event.ID, c.wantIDs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryIDsIncreaseAcrossRestarts(t *testing.T) {
+	boot := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := newMemory(10, 10, boot)
+	var last Event
+	for i := 0; i < 1000; i++ {
+		last = before.Publish("chirp", nil, nil)
+	}
+
+	after := newMemory(10, 10, boot.Add(time.Second))
+	next := after.Publish("chirp", nil, nil)
+	if next.ID <= last.ID {
+		t.Errorf("ID after restart = %d, want more than %d", next.ID, last.ID)
+	}
+}
+
+func TestMemoryTopicSubscription(t *testing.T) {
+	m := NewMemory(10, 1)
+	sub, _, _ := m.Subscribe(0, "follows:a")
+	defer m.Unsubscribe(sub)
+
+	// Events for other topics neither arrive nor count against the buffer.
+	m.Publish("follows_changed", []string{"follows:b"}, nil)
+	m.Publish("follows_changed", []string{"follows:b"}, nil)
+	want := m.Publish("follows_changed", []string{"follows:a"}, nil)
+
+	got, ok := <-sub.C
+	if !ok || got.ID != want.ID {
+		t.Errorf("received %v, %v, want %v", got, ok, want)
+	}
+}
+
+func TestMemoryDropsSlowSubscriber(t *testing.T) {
+	m := NewMemory(10, 1)
+	sub, _, _ := m.Subscribe(0)
+
+	m.Publish("chirp", nil, nil)
+	m.Publish("chirp", nil, nil)
+
+	<-sub.C
+	if _, ok := <-sub.C; ok {
+		t.Errorf("slow subscriber was not dropped")
+	}
+}
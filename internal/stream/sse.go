@@ -0,0 +1,30 @@
+package stream
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteEvent writes event in the text/event-stream wire format. Multi-line
+// data is split across several data fields as the spec requires.
+func WriteEvent(w io.Writer, event Event) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "id: %d\n", event.ID)
+	if event.Type != "" {
+		fmt.Fprintf(&b, "event: %s\n", event.Type)
+	}
+	for _, line := range strings.Split(string(event.Data), "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteComment writes an SSE comment line, used for heartbeats.
+func WriteComment(w io.Writer, comment string) error {
+	_, err := fmt.Fprintf(w, ": %s\n\n", comment)
+	return err
+}
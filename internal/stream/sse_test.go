@@ -0,0 +1,45 @@
+package stream
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteEvent(t *testing.T) {
+	type Case struct {
+		name  string
+		event Event
+		want  string
+	}
+
+	cases := []Case{
+		{
+			name:  "Single line",
+			event: Event{ID: 7, Type: "chirp_created", Data: []byte(`{"id":"x"}`)},
+			want:  "id: 7\nevent: chirp_created\ndata: {\"id\":\"x\"}\n\n",
+		},
+		{
+			name:  "Multi line data",
+			event: Event{ID: 8, Type: "chirp_created", Data: []byte("a\nb")},
+			want:  "id: 8\nevent: chirp_created\ndata: a\ndata: b\n\n",
+		},
+		{
+			name:  "No type",
+			event: Event{ID: 9, Data: []byte("x")},
+			want:  "id: 9\ndata: x\n\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var b strings.Builder
+			err := WriteEvent(&b, c.event)
+			if err != nil {
+				t.Fatalf("WriteEvent() error = %v", err)
+			}
+			if b.String() != c.want {
+				t.Errorf("WriteEvent() got = %q, want %q", b.String(), c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,390 @@
+// Package websocket implements the server side of RFC 6455: the opening
+// handshake, framing with fragmentation, and ping/pong/close control frames.
+// It covers what the API needs and nothing more: no extensions and no
+// subprotocol negotiation.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+
+	continuationFrame = 0
+)
+
+const (
+	CloseNormalClosure    = 1000
+	CloseGoingAway        = 1001
+	CloseProtocolError    = 1002
+	CloseInvalidPayload   = 1007
+	ClosePolicyViolation  = 1008
+	CloseMessageTooBig    = 1009
+	CloseInternalError    = 1011
+	CloseTryAgainLater    = 1013
+	closeNoStatusReceived = 1005
+)
+
+const (
+	acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	DefaultMaxMessageSize = 64 * 1024
+	DefaultWriteTimeout   = 10 * time.Second
+)
+
+var ErrClosed = errors.New("websocket: connection closed")
+
+// CloseError is returned by ReadMessage when the peer closes the connection
+// or sends something that forces the server to close it.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: close %d %s", e.Code, e.Reason)
+}
+
+type Conn struct {
+	conn           net.Conn
+	br             *bufio.Reader
+	writeMu        sync.Mutex
+	closeSent      bool
+	MaxMessageSize int64
+	WriteTimeout   time.Duration
+	// IdleTimeout bounds how long a read may wait for the next frame. Pongs
+	// count as frames, so pinging periodically keeps healthy peers alive.
+	IdleTimeout time.Duration
+}
+
+// AcceptKey computes the Sec-WebSocket-Accept value for a client key.
+func AcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Upgrade validates the opening handshake and takes over the connection.
+// On error nothing has been written, so the caller can still respond.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		return nil, errors.New("websocket: method must be GET")
+	}
+	if !headerContainsToken(r.Header, "Connection", "upgrade") {
+		return nil, errors.New("websocket: missing Connection: upgrade header")
+	}
+	if !headerContainsToken(r.Header, "Upgrade", "websocket") {
+		return nil, errors.New("websocket: missing Upgrade: websocket header")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, errors.New("websocket: unsupported version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(decoded) != 16 {
+		return nil, errors.New("websocket: invalid Sec-WebSocket-Key")
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("websocket: connection cannot be hijacked")
+	}
+	netConn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("websocket: hijack: %w", err)
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + AcceptKey(key) + "\r\n\r\n"
+	netConn.SetWriteDeadline(time.Now().Add(DefaultWriteTimeout))
+	_, err = netConn.Write([]byte(response))
+	if err != nil {
+		netConn.Close()
+		return nil, fmt.Errorf("websocket: writing handshake: %w", err)
+	}
+	netConn.SetDeadline(time.Time{})
+
+	return newConn(netConn, rw.Reader), nil
+}
+
+func newConn(netConn net.Conn, br *bufio.Reader) *Conn {
+	if br == nil {
+		br = bufio.NewReader(netConn)
+	}
+
+	return &Conn{
+		conn:           netConn,
+		br:             br,
+		MaxMessageSize: DefaultMaxMessageSize,
+		WriteTimeout:   DefaultWriteTimeout,
+	}
+}
+
+// ReadMessage returns the next text or binary message, reassembling
+// fragments. Pings are answered and pongs skipped. When the peer closes
+// the connection, or breaks the protocol, a close frame is sent back and
+// a *CloseError is returned.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	messageType := 0
+	message := []byte{}
+
+	for {
+		if c.IdleTimeout > 0 {
+			c.conn.SetReadDeadline(time.Now().Add(c.IdleTimeout))
+		}
+
+		f, err := readFrame(c.br, c.MaxMessageSize)
+		if err != nil {
+			var closeErr *CloseError
+			if errors.As(err, &closeErr) {
+				c.WriteClose(closeErr.Code, closeErr.Reason)
+			}
+			return 0, nil, err
+		}
+
+		switch f.opcode {
+		case PingMessage:
+			err = c.writeFrame(PongMessage, f.payload)
+			if err != nil {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			closeErr := parseClosePayload(f.payload)
+			code := closeErr.Code
+			if code == closeNoStatusReceived {
+				code = CloseNormalClosure
+			}
+			c.WriteClose(code, "")
+			return 0, nil, closeErr
+		case continuationFrame:
+			if messageType == 0 {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.fail(CloseProtocolError, "expected continuation frame")
+			}
+			messageType = f.opcode
+		default:
+			return 0, nil, c.fail(CloseProtocolError, "unknown opcode")
+		}
+
+		if int64(len(message)+len(f.payload)) > c.MaxMessageSize {
+			return 0, nil, c.fail(CloseMessageTooBig, "message too big")
+		}
+		message = append(message, f.payload...)
+
+		if f.fin {
+			if messageType == TextMessage && !utf8.Valid(message) {
+				return 0, nil, c.fail(CloseInvalidPayload, "invalid UTF-8")
+			}
+			return messageType, message, nil
+		}
+	}
+}
+
+func (c *Conn) fail(code int, reason string) error {
+	c.WriteClose(code, reason)
+	return &CloseError{Code: code, Reason: reason}
+}
+
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage && messageType != PingMessage {
+		return fmt.Errorf("websocket: cannot write message type %d", messageType)
+	}
+
+	return c.writeFrame(messageType, data)
+}
+
+// WriteClose starts the closing handshake. Only the first call sends a
+// frame; later calls are no-ops.
+func (c *Conn) WriteClose(code int, reason string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closeSent {
+		return nil
+	}
+	c.closeSent = true
+
+	// Control frame payloads are capped at 125 bytes, two of which are the code.
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	payload := make([]byte, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	copy(payload[2:], reason)
+
+	return c.writeFrameLocked(CloseMessage, payload)
+}
+
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Conn) writeFrame(opcode int, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closeSent {
+		return ErrClosed
+	}
+
+	return c.writeFrameLocked(opcode, payload)
+}
+
+func (c *Conn) writeFrameLocked(opcode int, payload []byte) error {
+	if c.WriteTimeout > 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(c.WriteTimeout))
+	}
+
+	_, err := c.conn.Write(encodeFrame(opcode, payload, nil))
+	return err
+}
+
+type frame struct {
+	fin     bool
+	opcode  int
+	payload []byte
+}
+
+// encodeFrame builds a single final frame. Servers never mask, so mask is
+// only set by tests acting as a client.
+func encodeFrame(opcode int, payload []byte, mask []byte) []byte {
+	header := []byte{0x80 | byte(opcode)}
+
+	maskBit := byte(0)
+	if mask != nil {
+		maskBit = 0x80
+	}
+
+	length := len(payload)
+	switch {
+	case length <= 125:
+		header = append(header, maskBit|byte(length))
+	case length <= 0xFFFF:
+		header = append(header, maskBit|126)
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header = append(header, maskBit|127)
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	out := append(header, mask...)
+	start := len(out)
+	out = append(out, payload...)
+	if mask != nil {
+		for i := range payload {
+			out[start+i] ^= mask[i%4]
+		}
+	}
+
+	return out
+}
+
+// readFrame reads one client frame. Client frames must be masked.
+func readFrame(br *bufio.Reader, maxPayload int64) (frame, error) {
+	header := make([]byte, 2)
+	_, err := io.ReadFull(br, header)
+	if err != nil {
+		return frame{}, err
+	}
+
+	f := frame{
+		fin:    header[0]&0x80 != 0,
+		opcode: int(header[0] & 0x0F),
+	}
+	if header[0]&0x70 != 0 {
+		return frame{}, &CloseError{Code: CloseProtocolError, Reason: "reserved bits set"}
+	}
+	if header[1]&0x80 == 0 {
+		return frame{}, &CloseError{Code: CloseProtocolError, Reason: "client frames must be masked"}
+	}
+
+	length := int64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		_, err = io.ReadFull(br, ext)
+		if err != nil {
+			return frame{}, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		_, err = io.ReadFull(br, ext)
+		if err != nil {
+			return frame{}, err
+		}
+		length = int64(binary.BigEndian.Uint64(ext))
+	}
+
+	isControl := f.opcode >= CloseMessage
+	if isControl && (length > 125 || !f.fin) {
+		return frame{}, &CloseError{Code: CloseProtocolError, Reason: "invalid control frame"}
+	}
+	if length < 0 || length > maxPayload {
+		return frame{}, &CloseError{Code: CloseMessageTooBig, Reason: "message too big"}
+	}
+
+	mask := make([]byte, 4)
+	_, err = io.ReadFull(br, mask)
+	if err != nil {
+		return frame{}, err
+	}
+
+	f.payload = make([]byte, length)
+	_, err = io.ReadFull(br, f.payload)
+	if err != nil {
+		return frame{}, err
+	}
+	for i := range f.payload {
+		f.payload[i] ^= mask[i%4]
+	}
+
+	return f, nil
+}
+
+func parseClosePayload(payload []byte) *CloseError {
+	if len(payload) < 2 {
+		return &CloseError{Code: closeNoStatusReceived}
+	}
+
+	return &CloseError{
+		Code:   int(binary.BigEndian.Uint16(payload)),
+		Reason: string(payload[2:]),
+	}
+}
+
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
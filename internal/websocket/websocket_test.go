@@ -0,0 +1,222 @@
+package websocket
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var testMask = []byte{0x12, 0x34, 0x56, 0x78}
+
+func TestAcceptKey(t *testing.T) {
+	// Example from RFC 6455 section 1.3.
+	got := AcceptKey("dGhlIHNhbXBsZSBub25jZQ==")
+	want := "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="
+	if got != want {
+		t.Errorf("AcceptKey() got = %v, want %v", got, want)
+	}
+}
+
+func fragment(opcode int, payload []byte, fin bool) []byte {
+	f := encodeFrame(opcode, payload, testMask)
+	if !fin {
+		f[0] &^= 0x80
+	}
+	return f
+}
+
+func TestReadMessage(t *testing.T) {
+	type Case struct {
+		name          string
+		input         [][]byte
+		wantType      int
+		wantData      string
+		wantCloseCode int
+		wantReplies   []int
+	}
+
+	cases := []Case{
+		{
+			name:     "Masked text",
+			input:    [][]byte{encodeFrame(TextMessage, []byte("hello"), testMask)},
+			wantType: TextMessage,
+			wantData: "hello",
+		},
+		{
+			name: "Fragmented with interleaved ping",
+			input: [][]byte{
+				fragment(TextMessage, []byte("hel"), false),
+				encodeFrame(PingMessage, []byte("p"), testMask),
+				fragment(continuationFrame, []byte("lo"), true),
+			},
+			wantType:    TextMessage,
+			wantData:    "hello",
+			wantReplies: []int{PongMessage},
+		},
+		{
+			name:     "Extended length",
+			input:    [][]byte{encodeFrame(BinaryMessage, []byte(strings.Repeat("a", 300)), testMask)},
+			wantType: BinaryMessage,
+			wantData: strings.Repeat("a", 300),
+		},
+		{
+			name:          "Unmasked frame",
+			input:         [][]byte{encodeFrame(TextMessage, []byte("hello"), nil)},
+			wantCloseCode: CloseProtocolError,
+			wantReplies:   []int{CloseMessage},
+		},
+		{
+			name:          "Peer close",
+			input:         [][]byte{encodeFrame(CloseMessage, []byte{0x03, 0xE8}, testMask)},
+			wantCloseCode: CloseNormalClosure,
+			wantReplies:   []int{CloseMessage},
+		},
+		{
+			name:          "Invalid UTF-8",
+			input:         [][]byte{encodeFrame(TextMessage, []byte{0xff, 0xfe}, testMask)},
+			wantCloseCode: CloseInvalidPayload,
+			wantReplies:   []int{CloseMessage},
+		},
+		{
+			name:          "Too big",
+			input:         [][]byte{encodeFrame(TextMessage, []byte(strings.Repeat("a", 20)), testMask)},
+			wantCloseCode: CloseMessageTooBig,
+			wantReplies:   []int{CloseMessage},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server, client := net.Pipe()
+			defer server.Close()
+			defer client.Close()
+
+			conn := newConn(server, nil)
+			if c.wantCloseCode == CloseMessageTooBig {
+				conn.MaxMessageSize = 10
+			}
+
+			go func() {
+				for _, b := range c.input {
+					client.Write(b)
+				}
+			}()
+
+			replies := make(chan int, len(c.wantReplies))
+			go func() {
+				br := bufio.NewReader(client)
+				for range c.wantReplies {
+					header := make([]byte, 2)
+					if _, err := br.Read(header); err != nil {
+						return
+					}
+					br.Discard(int(header[1] & 0x7F))
+					replies <- int(header[0] & 0x0F)
+				}
+			}()
+
+			gotType, gotData, err := conn.ReadMessage()
+			if c.wantCloseCode != 0 {
+				var closeErr *CloseError
+				if !errors.As(err, &closeErr) || closeErr.Code != c.wantCloseCode {
+					t.Fatalf("ReadMessage() error = %v, want close code %d", err, c.wantCloseCode)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("ReadMessage() error = %v", err)
+				}
+				if gotType != c.wantType || string(gotData) != c.wantData {
+					t.Errorf("ReadMessage() got = %d %q, want %d %q", gotType, gotData, c.wantType, c.wantData)
+				}
+			}
+
+			for _, want := range c.wantReplies {
+				if got := <-replies; got != want {
+					t.Errorf("reply opcode = %d, want %d", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	type Case struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+	}
+
+	valid := map[string]string{
+		"Connection":            "keep-alive, Upgrade",
+		"Upgrade":               "websocket",
+		"Sec-WebSocket-Version": "13",
+		"Sec-WebSocket-Key":     "dGhlIHNhbXBsZSBub25jZQ==",
+	}
+	without := func(name string) map[string]string {
+		h := map[string]string{}
+		for k, v := range valid {
+			if k != name {
+				h[k] = v
+			}
+		}
+		return h
+	}
+
+	cases := []Case{
+		{
+			name:       "Valid handshake",
+			headers:    valid,
+			wantStatus: http.StatusSwitchingProtocols,
+		},
+		{
+			name:       "Missing key",
+			headers:    without("Sec-WebSocket-Key"),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Missing upgrade",
+			headers:    without("Upgrade"),
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		conn.WriteClose(CloseNormalClosure, "")
+		conn.Close()
+	}))
+	defer server.Close()
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range c.headers {
+				req.Header.Set(k, v)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != c.wantStatus {
+				t.Errorf("Upgrade() status = %d, want %d", resp.StatusCode, c.wantStatus)
+			}
+			if c.wantStatus == http.StatusSwitchingProtocols && resp.Header.Get("Sec-WebSocket-Accept") != AcceptKey(c.headers["Sec-WebSocket-Key"]) {
+				t.Errorf("Upgrade() accept = %q", resp.Header.Get("Sec-WebSocket-Accept"))
+			}
+		})
+	}
+}
@@ -1,23 +1,35 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"net/http"
 	"os"
 	"sync/atomic"
+	"time"
 
 	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/delroscol98/chirpy/internal/moderation"
+	"github.com/delroscol98/chirpy/internal/storage"
+	"github.com/delroscol98/chirpy/internal/stream"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 )
 
 type apiConfig struct {
-	fileserverHits atomic.Int32
-	database       *database.Queries
-	platform       string
-	secret         string
-	polka_key      string
+	fileserverHits  atomic.Int32
+	db              *sql.DB
+	database        *database.Queries
+	platform        string
+	secret          string
+	polka_key       string
+	chirpEditWindow time.Duration
+	trashRetention  time.Duration
+	chirpStream     stream.Broadcaster
+	userStream      stream.Broadcaster
+	storage         storage.Storage
+	moderation      *moderation.Live
 }
 
 func main() {
@@ -27,36 +39,183 @@ func main() {
 	secret := os.Getenv("SECRET")
 	polka_key := os.Getenv("POLKA_KEY")
 
+	chirpEditWindow := 15 * time.Minute
+	if rawEditWindow := os.Getenv("CHIRP_EDIT_WINDOW"); rawEditWindow != "" {
+		window, err := time.ParseDuration(rawEditWindow)
+		if err != nil {
+			log.Fatal("Error parsing CHIRP_EDIT_WINDOW:", err)
+		}
+		chirpEditWindow = window
+	}
+
+	trashRetention := 30 * 24 * time.Hour
+	if rawRetention := os.Getenv("CHIRP_TRASH_RETENTION"); rawRetention != "" {
+		retention, err := time.ParseDuration(rawRetention)
+		if err != nil {
+			log.Fatal("Error parsing CHIRP_TRASH_RETENTION:", err)
+		}
+		trashRetention = retention
+	}
+
+	moderationReloadInterval := time.Minute
+	if rawInterval := os.Getenv("MODERATION_RELOAD_INTERVAL"); rawInterval != "" {
+		interval, err := time.ParseDuration(rawInterval)
+		if err != nil {
+			log.Fatal("Error parsing MODERATION_RELOAD_INTERVAL:", err)
+		}
+		moderationReloadInterval = interval
+	}
+
+	const filePathRoot = "."
+	const port = "8080"
+
+	// Uploads go to local disk unless MEDIA_STORAGE=s3. Local files are
+	// served by this server under /media/, so MEDIA_BASE_URL must be an
+	// absolute URL pointing back at it.
+	mediaStorage := os.Getenv("MEDIA_STORAGE")
+	mediaRoot := os.Getenv("MEDIA_ROOT")
+	if mediaRoot == "" {
+		mediaRoot = "./uploads"
+	}
+	var blobStorage storage.Storage
+	switch mediaStorage {
+	case "", "local":
+		mediaBaseURL := os.Getenv("MEDIA_BASE_URL")
+		if mediaBaseURL == "" {
+			mediaBaseURL = "http://localhost:" + port + "/media/"
+		}
+		local, err := storage.NewLocal(mediaRoot, mediaBaseURL)
+		if err != nil {
+			log.Fatal("Error setting up media storage:", err)
+		}
+		blobStorage = local
+	case "s3":
+		blobStorage = storage.NewS3(
+			os.Getenv("S3_ENDPOINT"),
+			os.Getenv("S3_BUCKET"),
+			os.Getenv("S3_REGION"),
+			os.Getenv("S3_ACCESS_KEY_ID"),
+			os.Getenv("S3_SECRET_ACCESS_KEY"),
+			os.Getenv("S3_PUBLIC_URL"),
+		)
+	default:
+		log.Fatal("Unknown MEDIA_STORAGE: ", mediaStorage)
+	}
+
 	db, err := sql.Open("postgres", dbURL)
 	if err != nil {
 		log.Fatal("Error opening database")
 	}
 	dbQueries := database.New(db)
 
-	const filePathRoot = "."
-	const port = "8080"
-
 	cfg := apiConfig{
-		database:  dbQueries,
-		platform:  platform,
-		secret:    secret,
-		polka_key: polka_key,
+		db:              db,
+		database:        dbQueries,
+		platform:        platform,
+		secret:          secret,
+		polka_key:       polka_key,
+		chirpEditWindow: chirpEditWindow,
+		trashRetention:  trashRetention,
+		chirpStream:     stream.NewMemory(1000, 64),
+		userStream:      stream.NewMemory(0, 64),
+		storage:         blobStorage,
 	}
 
+	// The word list comes from the moderation_words table unless
+	// MODERATION_WORDLIST names a file. Either is re-read periodically.
+	var wordSource moderation.Source = cfg.databaseModerationSource()
+	if wordListPath := os.Getenv("MODERATION_WORDLIST"); wordListPath != "" {
+		wordSource = moderation.FileSource{Path: wordListPath}
+	}
+	cfg.moderation, err = moderation.NewLive(context.Background(), wordSource)
+	if err != nil {
+		log.Fatal("Error loading moderation word list:", err)
+	}
+
+	go cfg.runMediaGC()
+	go cfg.runScheduledChirpPublisher()
+	go cfg.runTrashPurger()
+	go cfg.runModerationReloader(moderationReloadInterval)
+
 	handler := http.FileServer(http.Dir(filePathRoot))
 
 	serveMux := http.NewServeMux()
 	serveMux.Handle("/app/", http.StripPrefix("/app/", cfg.middlewareMetricsInc(handler)))
+	if _, ok := blobStorage.(*storage.Local); ok {
+		mediaHandler := http.FileServer(http.Dir(mediaRoot))
+		serveMux.Handle("/media/", http.StripPrefix("/media/", middlewareNoDirListing(mediaHandler)))
+	}
 
 	serveMux.HandleFunc("GET /api/healthz", handlerReadiness)
 
 	serveMux.HandleFunc("POST /api/chirps", cfg.handlerCreateChirp)
 	serveMux.HandleFunc("GET /api/chirps", cfg.handlerGetChirps)
 	serveMux.HandleFunc("GET /api/chirps/{chirpID}", cfg.handlerGetChirpById)
+	serveMux.HandleFunc("PUT /api/chirps/{chirpID}", cfg.handlerUpdateChirp)
+	serveMux.HandleFunc("PATCH /api/chirps/{chirpID}", cfg.handlerUpdateChirp)
 	serveMux.HandleFunc("DELETE /api/chirps/{chirpID}", cfg.handlerDeleteChirpByID)
+	serveMux.HandleFunc("GET /api/chirps/{chirpID}/history", cfg.handlerGetChirpHistory)
+	serveMux.HandleFunc("GET /api/chirps/{chirpID}/thread", cfg.handlerGetChirpThread)
+	serveMux.HandleFunc("POST /api/chirps/{chirpID}/likes", cfg.handlerLikeChirp)
+	serveMux.HandleFunc("DELETE /api/chirps/{chirpID}/likes", cfg.handlerUnlikeChirp)
+	serveMux.HandleFunc("POST /api/chirps/{chirpID}/rechirp", cfg.handlerRechirp)
+	serveMux.HandleFunc("DELETE /api/chirps/{chirpID}/rechirp", cfg.handlerUndoRechirp)
+	serveMux.HandleFunc("POST /api/chirps/{chirpID}/restore", cfg.handlerRestoreChirp)
+	serveMux.HandleFunc("POST /api/chirps/{chirpID}/votes", cfg.handlerVoteInPoll)
+	serveMux.HandleFunc("POST /api/chirps/{chirpID}/bookmark", cfg.handlerBookmarkChirp)
+	serveMux.HandleFunc("DELETE /api/chirps/{chirpID}/bookmark", cfg.handlerUnbookmarkChirp)
+	serveMux.HandleFunc("POST /api/chirps/{chirpID}/pin", cfg.handlerPinChirp)
+	serveMux.HandleFunc("DELETE /api/chirps/{chirpID}/pin", cfg.handlerUnpinChirp)
+	serveMux.HandleFunc("POST /api/chirps/{chirpID}/reports", cfg.handlerCreateReport)
+
+	serveMux.HandleFunc("POST /api/drafts", cfg.handlerCreateDraft)
+	serveMux.HandleFunc("GET /api/drafts", cfg.handlerGetDrafts)
+	serveMux.HandleFunc("GET /api/drafts/{draftID}", cfg.handlerGetDraftById)
+	serveMux.HandleFunc("PUT /api/drafts/{draftID}", cfg.handlerUpdateDraft)
+	serveMux.HandleFunc("DELETE /api/drafts/{draftID}", cfg.handlerDeleteDraft)
+	serveMux.HandleFunc("POST /api/drafts/{draftID}/publish", cfg.handlerPublishDraft)
+
+	serveMux.HandleFunc("GET /api/me/bookmarks", cfg.handlerGetBookmarks)
+	serveMux.HandleFunc("GET /api/me/trash", cfg.handlerGetTrash)
+	serveMux.HandleFunc("GET /api/me/scheduled", cfg.handlerGetScheduledChirps)
+	serveMux.HandleFunc("DELETE /api/me/scheduled/{scheduledID}", cfg.handlerCancelScheduledChirp)
 
 	serveMux.HandleFunc("POST /api/users", cfg.handlerCreateUsers)
 	serveMux.HandleFunc("PUT /api/users", cfg.handlerUpdatedUserEmailPassword)
+	serveMux.HandleFunc("PATCH /api/users/me", cfg.handlerUpdateUserProfile)
+	serveMux.HandleFunc("POST /api/users/me/avatar", cfg.handlerUploadAvatar)
+	serveMux.HandleFunc("GET /api/users/{handle}", cfg.handlerGetUserByHandle)
+	serveMux.HandleFunc("POST /api/users/{userID}/follow", cfg.handlerFollowUser)
+	serveMux.HandleFunc("DELETE /api/users/{userID}/follow", cfg.handlerUnfollowUser)
+	serveMux.HandleFunc("GET /api/users/{userID}/followers", cfg.handlerGetFollowers)
+	serveMux.HandleFunc("GET /api/users/{userID}/following", cfg.handlerGetFollowing)
+	serveMux.HandleFunc("GET /api/users/{userID}/likes", cfg.handlerGetUserLikes)
+	serveMux.HandleFunc("POST /api/users/{userID}/block", cfg.handlerBlockUser)
+	serveMux.HandleFunc("DELETE /api/users/{userID}/block", cfg.handlerUnblockUser)
+	serveMux.HandleFunc("POST /api/users/{userID}/mute", cfg.handlerMuteUser)
+	serveMux.HandleFunc("DELETE /api/users/{userID}/mute", cfg.handlerUnmuteUser)
+
+	serveMux.HandleFunc("POST /api/media", cfg.handlerUploadMedia)
+	serveMux.HandleFunc("GET /api/media/{mediaID}", cfg.handlerGetMediaById)
+
+	serveMux.HandleFunc("GET /api/timeline", cfg.handlerGetTimeline)
+
+	serveMux.HandleFunc("GET /api/stream/chirps", cfg.handlerStreamChirps)
+	serveMux.HandleFunc("GET /api/ws", cfg.handlerWebsocket)
+
+	serveMux.HandleFunc("GET /api/search/chirps", cfg.handlerSearchChirps)
+
+	serveMux.HandleFunc("GET /api/conversations", cfg.handlerGetConversations)
+	serveMux.HandleFunc("POST /api/conversations", cfg.handlerCreateConversation)
+	serveMux.HandleFunc("GET /api/conversations/{conversationID}/messages", cfg.handlerGetConversationMessages)
+	serveMux.HandleFunc("POST /api/conversations/{conversationID}/messages", cfg.handlerCreateConversationMessage)
+	serveMux.HandleFunc("POST /api/conversations/{conversationID}/read", cfg.handlerMarkConversationRead)
+
+	serveMux.HandleFunc("GET /api/notifications", cfg.handlerGetNotifications)
+	serveMux.HandleFunc("POST /api/notifications/read", cfg.handlerMarkNotificationsRead)
+
+	serveMux.HandleFunc("GET /api/hashtags/trending", cfg.handlerGetTrendingHashtags)
+	serveMux.HandleFunc("GET /api/hashtags/{tag}/chirps", cfg.handlerGetHashtagChirps)
 
 	serveMux.HandleFunc("POST /api/login", cfg.handlerGetUserByEmail)
 
@@ -70,6 +229,17 @@ func main() {
 
 	serveMux.HandleFunc("POST /admin/reset", cfg.handlerResetRequestsNumber)
 
+	serveMux.HandleFunc("GET /admin/moderation/reports", cfg.handlerAdminGetReports)
+	serveMux.HandleFunc("GET /admin/moderation/reports/{reportID}", cfg.handlerAdminGetReport)
+	serveMux.HandleFunc("PATCH /admin/moderation/reports/{reportID}", cfg.handlerAdminUpdateReport)
+	serveMux.HandleFunc("POST /admin/moderation/chirps/{chirpID}/hide", cfg.handlerAdminHideChirp)
+	serveMux.HandleFunc("DELETE /admin/moderation/chirps/{chirpID}/hide", cfg.handlerAdminUnhideChirp)
+	serveMux.HandleFunc("POST /admin/moderation/users/{userID}/suspend", cfg.handlerAdminSuspendUser)
+	serveMux.HandleFunc("DELETE /admin/moderation/users/{userID}/suspend", cfg.handlerAdminUnsuspendUser)
+	serveMux.HandleFunc("POST /admin/moderation/users/{userID}/ban", cfg.handlerAdminBanUser)
+	serveMux.HandleFunc("DELETE /admin/moderation/users/{userID}/ban", cfg.handlerAdminUnbanUser)
+	serveMux.HandleFunc("GET /admin/moderation/actions", cfg.handlerAdminGetModerationActions)
+
 	server := &http.Server{
 		Handler: serveMux,
 		Addr:    ":" + port,
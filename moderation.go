@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/delroscol98/chirpy/internal/moderation"
+)
+
+// databaseModerationSource loads the word list from the moderation_words
+// table.
+func (cfg *apiConfig) databaseModerationSource() moderation.Source {
+	return moderation.SourceFunc(func(ctx context.Context) ([]moderation.Rule, error) {
+		words, err := cfg.database.GetModerationWords(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		rules := make([]moderation.Rule, 0, len(words))
+		for _, word := range words {
+			rules = append(rules, moderation.Rule{
+				Word:        word.Word,
+				Mode:        moderation.Mode(word.MatchMode),
+				Strategy:    moderation.Strategy(word.Strategy),
+				Replacement: word.Replacement,
+			})
+		}
+
+		return rules, nil
+	})
+}
+
+// runModerationReloader picks up word list changes without a restart. A
+// failed reload keeps the last good list.
+func (cfg *apiConfig) runModerationReloader(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := cfg.moderation.Reload(context.Background())
+		if err != nil {
+			log.Printf("Error reloading moderation word list: %v", err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/delroscol98/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	notificationTypeLike      = "like"
+	notificationTypeReply     = "reply"
+	notificationTypeFollow    = "follow"
+	notificationTypeMention   = "mention"
+	notificationTypeChirpyRed = "chirpy_red"
+)
+
+// notificationBatch collects notifications created inside a transaction so
+// they are only pushed to live connections once it commits.
+type notificationBatch []database.Notification
+
+// notify records a notification for userID unless the user caused it.
+// actorID is uuid.Nil for system events such as a Chirpy Red upgrade.
+func (cfg *apiConfig) notify(ctx context.Context, q *database.Queries, batch *notificationBatch, userID, actorID uuid.UUID, notificationType string, chirpID uuid.NullUUID) error {
+	if userID == actorID {
+		return nil
+	}
+
+	notification, err := q.CreateNotification(ctx, database.CreateNotificationParams{
+		UserID:  userID,
+		ActorID: uuid.NullUUID{UUID: actorID, Valid: actorID != uuid.Nil},
+		Type:    notificationType,
+		ChirpID: chirpID,
+	})
+	if err != nil {
+		return fmt.Errorf("Error creating %s notification: %w", notificationType, err)
+	}
+	*batch = append(*batch, notification)
+
+	return nil
+}
+
+func (cfg *apiConfig) publishNotifications(batch notificationBatch) {
+	for _, notification := range batch {
+		cfg.publishEvent(cfg.userStream, streamEventNotification, []string{notificationsTopic(notification.UserID)}, databaseNotificationToResponse(notification))
+	}
+}
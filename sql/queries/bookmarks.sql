@@ -0,0 +1,36 @@
+-- name: BookmarkChirp :exec
+INSERT INTO bookmarks(
+  user_id,
+  chirp_id,
+  created_at
+) VALUES (
+  $1,
+  $2,
+  NOW()
+) ON CONFLICT DO NOTHING;
+
+-- name: UnbookmarkChirp :exec
+DELETE FROM bookmarks
+WHERE user_id = $1 AND chirp_id = $2;
+
+-- name: GetChirpIdsBookmarkedByUser :many
+SELECT chirp_id FROM bookmarks
+WHERE user_id = sqlc.arg('user_id')
+AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: GetBookmarkedChirpsPage :many
+SELECT sqlc.embed(chirps), bookmarks.created_at AS bookmarked_at
+FROM bookmarks
+JOIN chirps ON chirps.id = bookmarks.chirp_id
+WHERE bookmarks.user_id = sqlc.arg('user_id')
+AND chirps.deleted_at IS NULL
+AND chirps.hidden_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM restricted_users WHERE restricted_users.id = chirps.user_id
+)
+AND (
+  sqlc.narg('cursor_created_at')::timestamp IS NULL
+  OR (bookmarks.created_at, bookmarks.chirp_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY bookmarks.created_at DESC, bookmarks.chirp_id DESC
+LIMIT sqlc.arg('page_limit');
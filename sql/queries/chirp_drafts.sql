@@ -0,0 +1,47 @@
+-- name: CreateDraft :one
+INSERT INTO chirp_drafts(
+  id,
+  created_at,
+  updated_at,
+  user_id,
+  body,
+  in_reply_to,
+  quote_of
+) VALUES (
+  gen_random_uuid(),
+  NOW(),
+  NOW(),
+  $1,
+  $2,
+  $3,
+  $4
+) RETURNING *;
+
+-- name: GetDraftForUser :one
+SELECT * FROM chirp_drafts
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetDraftForUserForUpdate :one
+SELECT * FROM chirp_drafts
+WHERE id = $1 AND user_id = $2
+FOR UPDATE;
+
+-- name: GetDraftsPage :many
+SELECT * FROM chirp_drafts
+WHERE user_id = sqlc.arg('user_id')
+AND (
+  sqlc.narg('cursor_updated_at')::timestamp IS NULL
+  OR (updated_at, id) < (sqlc.narg('cursor_updated_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY updated_at DESC, id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: UpdateDraft :one
+UPDATE chirp_drafts
+SET body = $1, in_reply_to = $2, quote_of = $3, updated_at = NOW()
+WHERE id = $4 AND user_id = $5
+RETURNING *;
+
+-- name: DeleteDraft :execrows
+DELETE FROM chirp_drafts
+WHERE id = $1 AND user_id = $2;
@@ -0,0 +1,30 @@
+-- name: AddChirpMedia :exec
+INSERT INTO chirp_media(
+  chirp_id,
+  media_id,
+  position,
+  alt_text
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4
+);
+
+-- name: GetChirpMedia :many
+SELECT chirp_media.chirp_id, chirp_media.media_id, chirp_media.alt_text, media_uploads.content_type, media_uploads.storage_key, media_uploads.thumbnail_key, media_uploads.width, media_uploads.height
+FROM chirp_media
+JOIN media_uploads ON media_uploads.id = chirp_media.media_id
+WHERE chirp_media.chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY chirp_media.chirp_id, chirp_media.position;
+
+-- name: GetUsedMediaIds :many
+SELECT media_id FROM chirp_media
+WHERE media_id = ANY(sqlc.arg('media_ids')::uuid[])
+UNION
+SELECT media_id FROM scheduled_chirp_media
+WHERE media_id = ANY(sqlc.arg('media_ids')::uuid[]);
+
+-- name: DeleteChirpMedia :exec
+DELETE FROM chirp_media
+WHERE chirp_id = $1;
@@ -4,28 +4,239 @@ INSERT INTO chirps(
   created_at,
   updated_at,
   body,
-  user_id
+  user_id,
+  parent_id,
+  root_id,
+  quote_of
 ) VALUES (
   gen_random_uuid(),
   NOW(),
   NOW(),
   $1,
-  $2
+  $2,
+  $3,
+  $4,
+  $5
 ) RETURNING *;
 
--- name: GetAllChirpsAsc :many
+-- name: CreateRechirp :one
+INSERT INTO chirps(
+  id,
+  created_at,
+  updated_at,
+  body,
+  user_id,
+  rechirp_of
+) VALUES (
+  gen_random_uuid(),
+  NOW(),
+  NOW(),
+  '',
+  $1,
+  $2
+) ON CONFLICT (user_id, rechirp_of) WHERE rechirp_of IS NOT NULL DO NOTHING
+RETURNING *;
+
+-- name: GetUserRechirp :one
 SELECT * FROM chirps
-ORDER BY chirps.created_at ASC;
+WHERE chirps.user_id = sqlc.arg('user_id') AND chirps.rechirp_of = sqlc.arg('rechirp_of')::uuid;
+
+-- name: DeleteUserRechirp :exec
+DELETE FROM chirps
+WHERE user_id = sqlc.arg('user_id') AND rechirp_of = sqlc.arg('rechirp_of')::uuid;
 
--- name: GetAllChirpsByIdAsc :many
+-- name: GetChirpsPageAsc :many
 SELECT * FROM chirps
-WHERE user_id = $1
-ORDER BY chirps.created_at ASC;
+WHERE chirps.deleted_at IS NULL
+AND chirps.hidden_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM restricted_users WHERE restricted_users.id = chirps.user_id
+)
+AND (sqlc.narg('author_id')::uuid IS NULL OR chirps.user_id = sqlc.narg('author_id')::uuid)
+AND chirps.id IS DISTINCT FROM sqlc.narg('exclude_id')::uuid
+AND NOT EXISTS (
+  SELECT 1 FROM user_blocks
+  WHERE user_blocks.blocker_id = sqlc.arg('viewer_id') AND user_blocks.blocked_id = chirps.user_id
+)
+AND NOT EXISTS (
+  SELECT 1 FROM user_mutes
+  WHERE user_mutes.muter_id = sqlc.arg('viewer_id') AND user_mutes.muted_id = chirps.user_id
+)
+AND (
+  sqlc.narg('cursor_created_at')::timestamp IS NULL
+  OR (chirps.created_at, chirps.id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY chirps.created_at ASC, chirps.id ASC
+LIMIT sqlc.arg('page_limit');
+
+-- name: GetChirpsPageDesc :many
+SELECT * FROM chirps
+WHERE chirps.deleted_at IS NULL
+AND chirps.hidden_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM restricted_users WHERE restricted_users.id = chirps.user_id
+)
+AND (sqlc.narg('author_id')::uuid IS NULL OR chirps.user_id = sqlc.narg('author_id')::uuid)
+AND chirps.id IS DISTINCT FROM sqlc.narg('exclude_id')::uuid
+AND NOT EXISTS (
+  SELECT 1 FROM user_blocks
+  WHERE user_blocks.blocker_id = sqlc.arg('viewer_id') AND user_blocks.blocked_id = chirps.user_id
+)
+AND NOT EXISTS (
+  SELECT 1 FROM user_mutes
+  WHERE user_mutes.muter_id = sqlc.arg('viewer_id') AND user_mutes.muted_id = chirps.user_id
+)
+AND (
+  sqlc.narg('cursor_created_at')::timestamp IS NULL
+  OR (chirps.created_at, chirps.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('page_limit');
 
 -- name: GetChirpById :one
 SELECT * FROM chirps
 WHERE chirps.id = $1;
 
--- name: DeleteChirpById :exec
+-- name: GetChirpByIdForUpdate :one
+SELECT * FROM chirps
+WHERE chirps.id = $1
+FOR UPDATE;
+
+-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING *;
+
+-- name: GetThreadChirps :many
+SELECT * FROM chirps
+WHERE chirps.id = $1 OR chirps.root_id = $1
+ORDER BY chirps.created_at ASC, chirps.id ASC;
+
+-- name: CountChirpReplies :one
+SELECT COUNT(*) FROM chirps
+WHERE chirps.parent_id = sqlc.arg('chirp_id')::uuid;
+
+-- name: CountChirpQuotes :one
+SELECT COUNT(*) FROM chirps
+WHERE chirps.quote_of = sqlc.arg('chirp_id')::uuid;
+
+-- name: GetChirpsByIds :many
+SELECT * FROM chirps
+WHERE chirps.id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: GetRechirpCounts :many
+SELECT rechirp_of::uuid AS chirp_id, COUNT(*) AS rechirp_count
+FROM chirps
+WHERE rechirp_of = ANY(sqlc.arg('chirp_ids')::uuid[])
+AND deleted_at IS NULL
+GROUP BY rechirp_of;
+
+-- name: GetQuoteCounts :many
+SELECT quote_of::uuid AS chirp_id, COUNT(*) AS quote_count
+FROM chirps
+WHERE quote_of = ANY(sqlc.arg('chirp_ids')::uuid[])
+AND deleted_at IS NULL
+GROUP BY quote_of;
+
+-- name: SoftDeleteChirp :exec
+UPDATE chirps
+SET deleted_at = sqlc.arg('deleted_at')
+WHERE (id = sqlc.arg('id') OR rechirp_of = sqlc.arg('id'))
+AND deleted_at IS NULL;
+
+-- name: RestoreChirp :exec
+UPDATE chirps
+SET deleted_at = NULL
+WHERE (id = sqlc.arg('id') OR rechirp_of = sqlc.arg('id'))
+AND deleted_at = sqlc.arg('deleted_at')
+AND purged_at IS NULL;
+
+-- name: GetTrashPage :many
+SELECT * FROM chirps
+WHERE chirps.user_id = sqlc.arg('user_id')
+AND chirps.deleted_at >= sqlc.arg('deleted_after')
+AND chirps.purged_at IS NULL
+AND chirps.rechirp_of IS NULL
+AND (
+  sqlc.narg('cursor_deleted_at')::timestamp IS NULL
+  OR (chirps.deleted_at, chirps.id) < (sqlc.narg('cursor_deleted_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY chirps.deleted_at DESC, chirps.id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: DeleteExpiredChirps :execrows
 DELETE FROM chirps
-WHERE id = $1 AND user_id = $2;
+WHERE chirps.id IN (
+  SELECT expired.id FROM chirps AS expired
+  WHERE expired.deleted_at < sqlc.arg('deleted_before')
+  AND NOT EXISTS (
+    SELECT 1 FROM chirps AS referencing
+    WHERE referencing.parent_id = expired.id
+    OR referencing.root_id = expired.id
+    OR referencing.quote_of = expired.id
+  )
+  LIMIT sqlc.arg('page_limit')
+);
+
+-- name: PurgeExpiredChirps :many
+UPDATE chirps
+SET body = '', purged_at = NOW()
+WHERE chirps.id IN (
+  SELECT expired.id FROM chirps AS expired
+  WHERE expired.deleted_at < sqlc.arg('deleted_before')
+  AND expired.purged_at IS NULL
+  LIMIT sqlc.arg('page_limit')
+)
+RETURNING chirps.id;
+
+-- name: SearchChirps :many
+SELECT sqlc.embed(chirps), ts_rank(chirps.search_vector, to_tsquery('english', sqlc.arg('query')))::real AS rank
+FROM chirps
+WHERE chirps.search_vector @@ to_tsquery('english', sqlc.arg('query'))
+AND chirps.deleted_at IS NULL
+AND chirps.hidden_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM restricted_users WHERE restricted_users.id = chirps.user_id
+)
+AND (sqlc.narg('author_id')::uuid IS NULL OR chirps.user_id = sqlc.narg('author_id')::uuid)
+AND NOT EXISTS (
+  SELECT 1 FROM user_blocks
+  WHERE user_blocks.blocker_id = sqlc.arg('viewer_id') AND user_blocks.blocked_id = chirps.user_id
+)
+AND NOT EXISTS (
+  SELECT 1 FROM user_mutes
+  WHERE user_mutes.muter_id = sqlc.arg('viewer_id') AND user_mutes.muted_id = chirps.user_id
+)
+AND (sqlc.narg('since')::timestamp IS NULL OR chirps.created_at >= sqlc.narg('since')::timestamp)
+AND (sqlc.narg('until')::timestamp IS NULL OR chirps.created_at < sqlc.narg('until')::timestamp)
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('page_limit') OFFSET sqlc.arg('page_offset');
+
+-- name: GetPinnedChirp :one
+SELECT chirps.* FROM chirps
+JOIN users ON users.pinned_chirp_id = chirps.id
+WHERE users.id = sqlc.arg('author_id')
+AND chirps.deleted_at IS NULL
+AND chirps.hidden_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM restricted_users WHERE restricted_users.id = chirps.user_id
+)
+AND NOT EXISTS (
+  SELECT 1 FROM user_blocks
+  WHERE user_blocks.blocker_id = sqlc.arg('viewer_id') AND user_blocks.blocked_id = chirps.user_id
+)
+AND NOT EXISTS (
+  SELECT 1 FROM user_mutes
+  WHERE user_mutes.muter_id = sqlc.arg('viewer_id') AND user_mutes.muted_id = chirps.user_id
+);
+
+-- name: HideChirp :execrows
+UPDATE chirps
+SET hidden_at = NOW()
+WHERE id = $1 AND hidden_at IS NULL;
+
+-- name: UnhideChirp :execrows
+UPDATE chirps
+SET hidden_at = NULL
+WHERE id = $1 AND hidden_at IS NOT NULL;
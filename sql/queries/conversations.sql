@@ -0,0 +1,77 @@
+-- name: CreateConversation :one
+INSERT INTO conversations(
+  id,
+  created_at,
+  updated_at,
+  created_by
+) VALUES (
+  gen_random_uuid(),
+  NOW(),
+  NOW(),
+  $1
+) RETURNING *;
+
+-- name: AddConversationParticipant :exec
+INSERT INTO conversation_participants(
+  conversation_id,
+  user_id,
+  joined_at
+) VALUES (
+  $1,
+  $2,
+  NOW()
+);
+
+-- name: GetConversationForUser :one
+SELECT conversations.* FROM conversations
+JOIN conversation_participants ON conversation_participants.conversation_id = conversations.id
+WHERE conversations.id = sqlc.arg('id') AND conversation_participants.user_id = sqlc.arg('user_id');
+
+-- name: FindDirectConversation :one
+SELECT conversations.* FROM conversations
+JOIN conversation_participants AS mine ON mine.conversation_id = conversations.id AND mine.user_id = sqlc.arg('user_id')
+JOIN conversation_participants AS theirs ON theirs.conversation_id = conversations.id AND theirs.user_id = sqlc.arg('other_user_id')
+WHERE (
+  SELECT COUNT(*) FROM conversation_participants
+  WHERE conversation_participants.conversation_id = conversations.id
+) = 2
+LIMIT 1;
+
+-- name: GetConversationsPage :many
+SELECT conversations.* FROM conversations
+JOIN conversation_participants ON conversation_participants.conversation_id = conversations.id
+WHERE conversation_participants.user_id = sqlc.arg('user_id')
+AND (
+  sqlc.narg('cursor_updated_at')::timestamp IS NULL
+  OR (conversations.updated_at, conversations.id) < (sqlc.narg('cursor_updated_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY conversations.updated_at DESC, conversations.id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: TouchConversation :exec
+UPDATE conversations
+SET updated_at = $2
+WHERE id = $1;
+
+-- name: GetConversationParticipants :many
+SELECT * FROM conversation_participants
+WHERE conversation_id = ANY(sqlc.arg('conversation_ids')::uuid[])
+ORDER BY joined_at, user_id;
+
+-- name: MarkConversationRead :exec
+UPDATE conversation_participants
+SET last_read_at = GREATEST(COALESCE(last_read_at, sqlc.arg('read_at')::timestamp), sqlc.arg('read_at')::timestamp)
+WHERE conversation_id = sqlc.arg('conversation_id') AND user_id = sqlc.arg('user_id');
+
+-- name: GetConversationUnreadCounts :many
+SELECT direct_messages.conversation_id, COUNT(*) AS unread_count
+FROM direct_messages
+JOIN conversation_participants ON conversation_participants.conversation_id = direct_messages.conversation_id
+WHERE conversation_participants.user_id = sqlc.arg('user_id')
+AND direct_messages.conversation_id = ANY(sqlc.arg('conversation_ids')::uuid[])
+AND direct_messages.sender_id <> sqlc.arg('user_id')
+AND (
+  conversation_participants.last_read_at IS NULL
+  OR direct_messages.created_at > conversation_participants.last_read_at
+)
+GROUP BY direct_messages.conversation_id;
@@ -0,0 +1,30 @@
+-- name: CreateDirectMessage :one
+INSERT INTO direct_messages(
+  id,
+  created_at,
+  conversation_id,
+  sender_id,
+  body
+) VALUES (
+  gen_random_uuid(),
+  NOW(),
+  $1,
+  $2,
+  $3
+) RETURNING *;
+
+-- name: GetDirectMessagesPage :many
+SELECT * FROM direct_messages
+WHERE direct_messages.conversation_id = sqlc.arg('conversation_id')
+AND (
+  sqlc.narg('cursor_created_at')::timestamp IS NULL
+  OR (direct_messages.created_at, direct_messages.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY direct_messages.created_at DESC, direct_messages.id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: GetLatestDirectMessages :many
+SELECT DISTINCT ON (direct_messages.conversation_id) direct_messages.*
+FROM direct_messages
+WHERE direct_messages.conversation_id = ANY(sqlc.arg('conversation_ids')::uuid[])
+ORDER BY direct_messages.conversation_id, direct_messages.created_at DESC, direct_messages.id DESC;
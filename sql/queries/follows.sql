@@ -0,0 +1,80 @@
+-- name: FollowUser :execrows
+INSERT INTO follows(
+  follower_id,
+  followee_id,
+  created_at
+) VALUES (
+  $1,
+  $2,
+  NOW()
+) ON CONFLICT DO NOTHING;
+
+-- name: UnfollowUser :exec
+DELETE FROM follows
+WHERE follower_id = $1 AND followee_id = $2;
+
+-- name: RemoveFollowsBetween :exec
+DELETE FROM follows
+WHERE (follower_id = sqlc.arg('user_id') AND followee_id = sqlc.arg('other_user_id'))
+OR (follower_id = sqlc.arg('other_user_id') AND followee_id = sqlc.arg('user_id'));
+
+-- name: GetFollowersPage :many
+SELECT sqlc.embed(users), follows.created_at AS followed_at
+FROM follows
+JOIN users ON users.id = follows.follower_id
+WHERE follows.followee_id = sqlc.arg('user_id')
+AND (
+  sqlc.narg('cursor_created_at')::timestamp IS NULL
+  OR (follows.created_at, follows.follower_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY follows.created_at DESC, follows.follower_id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: GetFollowingPage :many
+SELECT sqlc.embed(users), follows.created_at AS followed_at
+FROM follows
+JOIN users ON users.id = follows.followee_id
+WHERE follows.follower_id = sqlc.arg('user_id')
+AND (
+  sqlc.narg('cursor_created_at')::timestamp IS NULL
+  OR (follows.created_at, follows.followee_id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY follows.created_at DESC, follows.followee_id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: GetTimelinePage :many
+SELECT chirps.* FROM chirps
+JOIN follows ON follows.followee_id = chirps.user_id
+WHERE follows.follower_id = sqlc.arg('user_id')
+AND chirps.deleted_at IS NULL
+AND chirps.hidden_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM restricted_users WHERE restricted_users.id = chirps.user_id
+)
+AND NOT EXISTS (
+  SELECT 1 FROM user_blocks
+  WHERE user_blocks.blocker_id = sqlc.arg('user_id') AND user_blocks.blocked_id = chirps.user_id
+)
+AND NOT EXISTS (
+  SELECT 1 FROM user_mutes
+  WHERE user_mutes.muter_id = sqlc.arg('user_id') AND user_mutes.muted_id = chirps.user_id
+)
+AND (
+  sqlc.narg('cursor_created_at')::timestamp IS NULL
+  OR (chirps.created_at, chirps.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: GetFolloweeIds :many
+SELECT followee_id FROM follows
+WHERE follower_id = $1
+AND NOT EXISTS (
+  SELECT 1 FROM user_mutes
+  WHERE user_mutes.muter_id = follows.follower_id AND user_mutes.muted_id = follows.followee_id
+);
+
+-- name: GetUserFollowCounts :one
+SELECT
+  (SELECT COUNT(*) FROM follows WHERE follows.followee_id = sqlc.arg('user_id')) AS follower_count,
+  (SELECT COUNT(*) FROM follows WHERE follows.follower_id = sqlc.arg('user_id')) AS following_count;
@@ -0,0 +1,57 @@
+-- name: CreateMediaUpload :one
+INSERT INTO media_uploads(
+  id,
+  created_at,
+  user_id,
+  purpose,
+  content_type,
+  storage_key,
+  thumbnail_key,
+  width,
+  height,
+  size_bytes
+) VALUES (
+  $1,
+  NOW(),
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8,
+  $9
+)
+RETURNING *;
+
+-- name: GetMediaUploadById :one
+SELECT * FROM media_uploads
+WHERE id = $1;
+
+-- name: GetMediaUploadsByIdsForUpdate :many
+SELECT * FROM media_uploads
+WHERE id = ANY(sqlc.arg('ids')::uuid[])
+FOR UPDATE;
+
+-- name: GetOrphanedMediaUploads :many
+SELECT * FROM media_uploads
+WHERE created_at < $1
+  AND NOT EXISTS (
+    SELECT 1 FROM chirp_media
+    WHERE chirp_media.media_id = media_uploads.id
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM scheduled_chirp_media
+    WHERE scheduled_chirp_media.media_id = media_uploads.id
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.avatar_upload_id = media_uploads.id
+  )
+ORDER BY created_at
+LIMIT $2
+FOR UPDATE SKIP LOCKED;
+
+-- name: DeleteMediaUpload :exec
+DELETE FROM media_uploads
+WHERE id = $1;
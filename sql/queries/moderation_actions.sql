@@ -0,0 +1,35 @@
+-- name: CreateModerationAction :one
+INSERT INTO moderation_actions(
+  id,
+  created_at,
+  admin_id,
+  action,
+  report_id,
+  chirp_id,
+  target_user_id,
+  note,
+  reason,
+  expires_at
+) VALUES (
+  gen_random_uuid(),
+  NOW(),
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8
+) RETURNING *;
+
+-- name: GetModerationActionsPage :many
+SELECT * FROM moderation_actions
+WHERE (sqlc.narg('chirp_id')::uuid IS NULL OR chirp_id = sqlc.narg('chirp_id')::uuid)
+AND (sqlc.narg('target_user_id')::uuid IS NULL OR target_user_id = sqlc.narg('target_user_id')::uuid)
+AND (
+  sqlc.narg('cursor_created_at')::timestamp IS NULL
+  OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_limit');
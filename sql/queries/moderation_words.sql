@@ -0,0 +1,3 @@
+-- name: GetModerationWords :many
+SELECT * FROM moderation_words
+ORDER BY word;
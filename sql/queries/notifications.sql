@@ -0,0 +1,38 @@
+-- name: CreateNotification :one
+INSERT INTO notifications(
+  id,
+  created_at,
+  user_id,
+  actor_id,
+  type,
+  chirp_id
+) VALUES (
+  gen_random_uuid(),
+  NOW(),
+  $1,
+  $2,
+  $3,
+  $4
+) RETURNING *;
+
+-- name: GetNotificationsPage :many
+SELECT * FROM notifications
+WHERE notifications.user_id = sqlc.arg('user_id')
+AND (NOT sqlc.arg('unread_only')::boolean OR notifications.read_at IS NULL)
+AND (
+  sqlc.narg('cursor_created_at')::timestamp IS NULL
+  OR (notifications.created_at, notifications.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY notifications.created_at DESC, notifications.id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*) FROM notifications
+WHERE notifications.user_id = $1 AND notifications.read_at IS NULL;
+
+-- name: MarkNotificationsRead :execrows
+UPDATE notifications
+SET read_at = NOW()
+WHERE user_id = sqlc.arg('user_id')
+AND read_at IS NULL
+AND (cardinality(sqlc.arg('ids')::uuid[]) = 0 OR id = ANY(sqlc.arg('ids')::uuid[]));
@@ -0,0 +1,57 @@
+-- name: CreatePoll :one
+INSERT INTO polls(
+  chirp_id,
+  created_at,
+  closes_at
+) VALUES (
+  $1,
+  NOW(),
+  $2
+) RETURNING *;
+
+-- name: AddPollOption :exec
+INSERT INTO poll_options(
+  id,
+  chirp_id,
+  position,
+  label
+) VALUES (
+  gen_random_uuid(),
+  $1,
+  $2,
+  $3
+);
+
+-- name: GetPollByChirpId :one
+SELECT * FROM polls
+WHERE chirp_id = $1;
+
+-- name: GetPollsByChirpIds :many
+SELECT * FROM polls
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: GetPollOptionTallies :many
+SELECT poll_options.id, poll_options.chirp_id, poll_options.label, COUNT(poll_votes.user_id) AS vote_count
+FROM poll_options
+LEFT JOIN poll_votes ON poll_votes.option_id = poll_options.id
+WHERE poll_options.chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+GROUP BY poll_options.id
+ORDER BY poll_options.chirp_id, poll_options.position;
+
+-- name: GetUserPollVotes :many
+SELECT chirp_id, option_id FROM poll_votes
+WHERE user_id = sqlc.arg('user_id')
+AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: CastPollVote :execrows
+INSERT INTO poll_votes(
+  chirp_id,
+  user_id,
+  option_id,
+  created_at
+) VALUES (
+  $1,
+  $2,
+  $3,
+  NOW()
+) ON CONFLICT DO NOTHING;
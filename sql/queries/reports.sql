@@ -0,0 +1,52 @@
+-- name: CreateReport :one
+INSERT INTO reports(
+  id,
+  created_at,
+  updated_at,
+  chirp_id,
+  reporter_id,
+  reason,
+  details
+) VALUES (
+  gen_random_uuid(),
+  NOW(),
+  NOW(),
+  $1,
+  $2,
+  $3,
+  $4
+)
+ON CONFLICT (chirp_id, reporter_id) DO NOTHING
+RETURNING *;
+
+-- name: GetReportById :one
+SELECT * FROM reports
+WHERE id = $1;
+
+-- name: GetReportByIdForUpdate :one
+SELECT * FROM reports
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetReportsPage :many
+SELECT * FROM reports
+WHERE status = ANY(sqlc.arg('statuses')::text[])
+AND (
+  sqlc.narg('cursor_created_at')::timestamp IS NULL
+  OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('page_limit');
+
+-- name: UpdateReportStatus :one
+UPDATE reports
+SET status = $1, handled_by = $2, updated_at = NOW()
+WHERE id = $3
+RETURNING *;
+
+-- name: ResolveOpenReportsForChirp :many
+UPDATE reports
+SET status = 'resolved', handled_by = sqlc.arg('handled_by'), updated_at = NOW()
+WHERE chirp_id = sqlc.arg('chirp_id')
+AND status IN ('open', 'triaged')
+RETURNING id;
@@ -0,0 +1,74 @@
+-- name: CreateScheduledChirp :one
+INSERT INTO scheduled_chirps(
+  id,
+  created_at,
+  updated_at,
+  user_id,
+  body,
+  in_reply_to,
+  quote_of,
+  publish_at
+) VALUES (
+  gen_random_uuid(),
+  NOW(),
+  NOW(),
+  $1,
+  $2,
+  $3,
+  $4,
+  $5
+) RETURNING *;
+
+-- name: AddScheduledChirpMedia :exec
+INSERT INTO scheduled_chirp_media(
+  scheduled_chirp_id,
+  media_id,
+  position,
+  alt_text
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4
+);
+
+-- name: GetScheduledChirpMedia :many
+SELECT scheduled_chirp_media.scheduled_chirp_id, scheduled_chirp_media.media_id, scheduled_chirp_media.alt_text, media_uploads.content_type, media_uploads.storage_key, media_uploads.thumbnail_key, media_uploads.width, media_uploads.height
+FROM scheduled_chirp_media
+JOIN media_uploads ON media_uploads.id = scheduled_chirp_media.media_id
+WHERE scheduled_chirp_media.scheduled_chirp_id = ANY(sqlc.arg('scheduled_chirp_ids')::uuid[])
+ORDER BY scheduled_chirp_media.scheduled_chirp_id, scheduled_chirp_media.position;
+
+-- name: GetScheduledChirpsPage :many
+SELECT * FROM scheduled_chirps
+WHERE user_id = sqlc.arg('user_id')
+AND (
+  sqlc.narg('cursor_publish_at')::timestamp IS NULL
+  OR (publish_at, id) > (sqlc.narg('cursor_publish_at')::timestamp, sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY publish_at ASC, id ASC
+LIMIT sqlc.arg('page_limit');
+
+-- name: DeleteScheduledChirp :execrows
+DELETE FROM scheduled_chirps
+WHERE id = $1 AND user_id = $2;
+
+-- name: ClaimDueScheduledChirp :one
+SELECT * FROM scheduled_chirps
+WHERE failed_at IS NULL AND publish_at <= $1
+ORDER BY publish_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkScheduledChirpFailed :exec
+WITH released_media AS (
+  DELETE FROM scheduled_chirp_media
+  WHERE scheduled_chirp_media.scheduled_chirp_id = $1
+)
+UPDATE scheduled_chirps
+SET failed_at = NOW(), failure_reason = $2, updated_at = NOW()
+WHERE id = $1 AND failed_at IS NULL;
+
+-- name: DeletePendingScheduledChirps :exec
+DELETE FROM scheduled_chirps
+WHERE user_id = $1 AND failed_at IS NULL;
@@ -0,0 +1,28 @@
+-- name: BlockUser :exec
+INSERT INTO user_blocks(
+  blocker_id,
+  blocked_id,
+  created_at
+) VALUES (
+  $1,
+  $2,
+  NOW()
+) ON CONFLICT DO NOTHING;
+
+-- name: UnblockUser :exec
+DELETE FROM user_blocks
+WHERE blocker_id = $1 AND blocked_id = $2;
+
+-- name: HasBlockBetween :one
+SELECT EXISTS(
+  SELECT 1 FROM user_blocks
+  WHERE (user_blocks.blocker_id = sqlc.arg('user_id') AND user_blocks.blocked_id = ANY(sqlc.arg('other_user_ids')::uuid[]))
+  OR (user_blocks.blocked_id = sqlc.arg('user_id') AND user_blocks.blocker_id = ANY(sqlc.arg('other_user_ids')::uuid[]))
+);
+
+-- name: HasBlockAmong :one
+SELECT EXISTS(
+  SELECT 1 FROM user_blocks
+  WHERE user_blocks.blocker_id = ANY(sqlc.arg('user_ids')::uuid[])
+  AND user_blocks.blocked_id = ANY(sqlc.arg('user_ids')::uuid[])
+);
@@ -0,0 +1,24 @@
+-- name: MuteUser :exec
+INSERT INTO user_mutes(
+  muter_id,
+  muted_id,
+  created_at
+) VALUES (
+  $1,
+  $2,
+  NOW()
+) ON CONFLICT DO NOTHING;
+
+-- name: UnmuteUser :exec
+DELETE FROM user_mutes
+WHERE muter_id = $1 AND muted_id = $2;
+
+-- name: GetHiddenAuthorIds :many
+SELECT user_blocks.blocked_id AS user_id FROM user_blocks
+WHERE user_blocks.blocker_id = sqlc.arg('user_id')
+UNION
+SELECT user_blocks.blocker_id FROM user_blocks
+WHERE user_blocks.blocked_id = sqlc.arg('user_id')
+UNION
+SELECT user_mutes.muted_id FROM user_mutes
+WHERE user_mutes.muter_id = sqlc.arg('user_id');
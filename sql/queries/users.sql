@@ -4,13 +4,15 @@ INSERT INTO users (
   created_at,
   updated_at,
   email,
-  hashed_password
+  hashed_password,
+  handle
 ) VALUES (
   gen_random_uuid(),
   NOW(),
   NOW(),
   $1,
-  $2
+  $2,
+  $3
 )
 RETURNING *;
 
@@ -30,5 +32,64 @@ RETURNING *;
 -- name: UpgradeUserChirpyRed :one
 UPDATE users
 SET is_chirpy_red = true
-WHERE id = $1
+WHERE id = $1 AND NOT is_chirpy_red
 RETURNING *;
+
+-- name: GetUserById :one
+SELECT * FROM users
+WHERE users.id = $1;
+
+-- name: UpdateUserHandle :one
+UPDATE users
+SET handle = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING *;
+
+-- name: GetUsersByHandles :many
+SELECT * FROM users
+WHERE LOWER(users.handle) = ANY(sqlc.arg('handles')::text[]);
+
+-- name: GetUsersByIds :many
+SELECT * FROM users
+WHERE users.id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: GetUserByHandle :one
+SELECT * FROM users
+WHERE LOWER(users.handle) = LOWER(sqlc.arg('handle'));
+
+-- name: UpdateUserProfile :one
+UPDATE users
+SET handle = $1, display_name = $2, bio = $3, avatar_url = $4, updated_at = NOW(),
+  avatar_upload_id = CASE WHEN users.avatar_url = $4 THEN users.avatar_upload_id END
+WHERE id = $5
+RETURNING *;
+
+-- name: UpdateUserAvatarUrl :one
+UPDATE users
+SET avatar_url = $1, avatar_upload_id = $2, updated_at = NOW()
+WHERE id = $3
+RETURNING *;
+
+-- name: PinUserChirp :exec
+UPDATE users
+SET pinned_chirp_id = $1, updated_at = NOW()
+WHERE id = $2;
+
+-- name: UnpinUserChirp :exec
+UPDATE users
+SET pinned_chirp_id = NULL, updated_at = NOW()
+WHERE id = $1 AND pinned_chirp_id = $2;
+
+-- name: GetUserByIdForUpdate :one
+SELECT * FROM users
+WHERE users.id = $1
+FOR UPDATE;
+
+-- name: GetUserAccountStatus :one
+SELECT account_status, status_reason, status_expires_at FROM users
+WHERE users.id = $1;
+
+-- name: SetUserAccountStatus :exec
+UPDATE users
+SET account_status = $1, status_reason = $2, status_expires_at = $3, updated_at = NOW()
+WHERE id = $4;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN quote_of UUID REFERENCES chirps(id) ON DELETE SET NULL,
+ADD COLUMN rechirp_of UUID REFERENCES chirps(id) ON DELETE CASCADE;
+
+CREATE INDEX chirps_quote_of_idx ON chirps (quote_of);
+CREATE INDEX chirps_rechirp_of_idx ON chirps (rechirp_of);
+CREATE UNIQUE INDEX chirps_user_id_rechirp_of_key ON chirps (user_id, rechirp_of) WHERE rechirp_of IS NOT NULL;
+
+-- +goose Down
+ALTER TABLE chirps
+DROP COLUMN rechirp_of,
+DROP COLUMN quote_of;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE media_uploads(
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  purpose TEXT NOT NULL,
+  content_type TEXT NOT NULL,
+  storage_key TEXT NOT NULL UNIQUE,
+  thumbnail_key TEXT NOT NULL UNIQUE,
+  width INTEGER NOT NULL,
+  height INTEGER NOT NULL,
+  size_bytes BIGINT NOT NULL
+);
+
+CREATE INDEX media_uploads_user_id_created_at_idx ON media_uploads (user_id, created_at);
+
+-- +goose Down
+DROP TABLE media_uploads;
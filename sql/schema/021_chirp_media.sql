@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE chirp_media(
+  chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+  media_id UUID NOT NULL UNIQUE REFERENCES media_uploads(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  alt_text TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (chirp_id, position),
+  CHECK (position >= 0 AND position < 4)
+);
+
+-- +goose Down
+DROP TABLE chirp_media;
@@ -0,0 +1,32 @@
+-- +goose Up
+-- in_reply_to and quote_of are deliberately not foreign keys: if the
+-- target disappears before publish_at, publishing fails and the reason is
+-- recorded instead of the chirp silently losing its context.
+CREATE TABLE scheduled_chirps(
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  body TEXT NOT NULL,
+  in_reply_to UUID,
+  quote_of UUID,
+  publish_at TIMESTAMP NOT NULL,
+  failed_at TIMESTAMP,
+  failure_reason TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX scheduled_chirps_due_idx ON scheduled_chirps (publish_at) WHERE failed_at IS NULL;
+CREATE INDEX scheduled_chirps_user_id_publish_at_idx ON scheduled_chirps (user_id, publish_at, id);
+
+CREATE TABLE scheduled_chirp_media(
+  scheduled_chirp_id UUID NOT NULL REFERENCES scheduled_chirps(id) ON DELETE CASCADE,
+  media_id UUID NOT NULL UNIQUE REFERENCES media_uploads(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  alt_text TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (scheduled_chirp_id, position),
+  CHECK (position >= 0 AND position < 4)
+);
+
+-- +goose Down
+DROP TABLE scheduled_chirp_media;
+DROP TABLE scheduled_chirps;
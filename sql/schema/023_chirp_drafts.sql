@@ -0,0 +1,17 @@
+-- +goose Up
+-- Like scheduled_chirps, the reply and quote targets are checked when the
+-- draft is published rather than enforced with foreign keys.
+CREATE TABLE chirp_drafts(
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  body TEXT NOT NULL,
+  in_reply_to UUID,
+  quote_of UUID
+);
+
+CREATE INDEX chirp_drafts_user_id_updated_at_idx ON chirp_drafts (user_id, updated_at, id);
+
+-- +goose Down
+DROP TABLE chirp_drafts;
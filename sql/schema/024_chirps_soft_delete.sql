@@ -0,0 +1,17 @@
+-- +goose Up
+-- Deleted chirps keep their content until the trash retention window
+-- passes. purged_at marks chirps whose content has been wiped but whose
+-- row is kept because replies or quotes still point at it. Chirps
+-- tombstoned before this migration had their bodies cleared already.
+ALTER TABLE chirps RENAME COLUMN tombstoned_at TO deleted_at;
+ALTER TABLE chirps ADD COLUMN purged_at TIMESTAMP;
+UPDATE chirps SET purged_at = deleted_at WHERE deleted_at IS NOT NULL;
+
+CREATE INDEX chirps_deleted_at_idx ON chirps (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX chirps_user_id_deleted_at_idx ON chirps (user_id, deleted_at, id) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX chirps_user_id_deleted_at_idx;
+DROP INDEX chirps_deleted_at_idx;
+ALTER TABLE chirps DROP COLUMN purged_at;
+ALTER TABLE chirps RENAME COLUMN deleted_at TO tombstoned_at;
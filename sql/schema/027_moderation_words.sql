@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE moderation_words(
+  word TEXT PRIMARY KEY,
+  match_mode TEXT NOT NULL DEFAULT 'whole' CHECK (match_mode IN ('whole', 'substring')),
+  strategy TEXT NOT NULL DEFAULT 'mask' CHECK (strategy IN ('mask', 'stars', 'replace')),
+  replacement TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO moderation_words(word) VALUES
+  ('kerfuffle'),
+  ('sharbert'),
+  ('fornax');
+
+-- +goose Down
+DROP TABLE moderation_words;
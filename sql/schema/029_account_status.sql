@@ -0,0 +1,34 @@
+-- +goose Up
+-- Suspensions lapse once status_expires_at passes; bans never expire.
+ALTER TABLE users
+ADD COLUMN account_status TEXT NOT NULL DEFAULT 'active' CHECK (account_status IN ('active', 'suspended', 'banned')),
+ADD COLUMN status_reason TEXT NOT NULL DEFAULT '',
+ADD COLUMN status_expires_at TIMESTAMP;
+
+UPDATE users
+SET account_status = 'suspended'
+WHERE suspended_at IS NOT NULL;
+
+ALTER TABLE users
+DROP COLUMN suspended_at;
+
+ALTER TABLE moderation_actions
+ADD COLUMN reason TEXT NOT NULL DEFAULT '',
+ADD COLUMN expires_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE moderation_actions
+DROP COLUMN expires_at,
+DROP COLUMN reason;
+
+ALTER TABLE users
+ADD COLUMN suspended_at TIMESTAMP;
+
+UPDATE users
+SET suspended_at = updated_at
+WHERE account_status <> 'active';
+
+ALTER TABLE users
+DROP COLUMN status_expires_at,
+DROP COLUMN status_reason,
+DROP COLUMN account_status;
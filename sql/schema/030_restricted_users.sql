@@ -0,0 +1,10 @@
+-- +goose Up
+-- Accounts that are currently locked out. Listings exclude their chirps;
+-- a suspension drops out of the view by itself once it expires.
+CREATE VIEW restricted_users AS
+SELECT users.id FROM users
+WHERE users.account_status <> 'active'
+AND (users.status_expires_at IS NULL OR users.status_expires_at > NOW());
+
+-- +goose Down
+DROP VIEW restricted_users;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Tracks which upload backs the current avatar so replaced ones can be
+-- garbage collected. Existing avatars are assumed to be the latest upload.
+ALTER TABLE users
+ADD COLUMN avatar_upload_id UUID REFERENCES media_uploads(id) ON DELETE SET NULL;
+
+UPDATE users
+SET avatar_upload_id = (
+  SELECT media_uploads.id FROM media_uploads
+  WHERE media_uploads.user_id = users.id AND media_uploads.purpose = 'avatar'
+  ORDER BY media_uploads.created_at DESC
+  LIMIT 1
+)
+WHERE users.avatar_url <> '';
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN avatar_upload_id;
@@ -1,27 +1,152 @@
 package main
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type ChirpRequestBody struct {
-	Body   string    `json:"body"`
-	UserID uuid.UUID `json:"user_id"`
+	Body      string                  `json:"body"`
+	UserID    uuid.UUID               `json:"user_id"`
+	InReplyTo *uuid.UUID              `json:"in_reply_to"`
+	QuoteOf   *uuid.UUID              `json:"quote_of"`
+	Media     []ChirpMediaRequestBody `json:"media"`
+	Poll      *PollRequestBody        `json:"poll"`
+	// PublishAt defers the chirp until then instead of posting it now.
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// ChirpEditRequestBody is the only shape an edit accepts.
+type ChirpEditRequestBody struct {
+	Body string `json:"body"`
+}
+
+type PollRequestBody struct {
+	Options  []string  `json:"options"`
+	ClosesAt time.Time `json:"closes_at"`
+}
+
+// ChirpMediaRequestBody attaches an upload from POST /api/media.
+type ChirpMediaRequestBody struct {
+	ID      uuid.UUID `json:"id"`
+	AltText string    `json:"alt_text"`
 }
 
 type ChirpResponseBody struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Body      string    `json:"body"`
-	UserID    uuid.UUID `json:"user_id"`
+	ID             uuid.UUID                `json:"id"`
+	CreatedAt      time.Time                `json:"created_at"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+	Body           string                   `json:"body"`
+	UserID         uuid.UUID                `json:"user_id"`
+	Deleted        bool                     `json:"deleted"`
+	Hidden         bool                     `json:"hidden"`
+	InReplyTo      *uuid.UUID               `json:"in_reply_to,omitempty"`
+	RootID         *uuid.UUID               `json:"root_id,omitempty"`
+	QuoteOf        *ChirpResponseBody       `json:"quote_of,omitempty"`
+	RechirpOf      *ChirpResponseBody       `json:"rechirp_of,omitempty"`
+	LikeCount      int64                    `json:"like_count"`
+	LikedByMe      bool                     `json:"liked_by_me"`
+	BookmarkedByMe bool                     `json:"bookmarked_by_me"`
+	Pinned         bool                     `json:"pinned,omitempty"`
+	RechirpCount   int64                    `json:"rechirp_count"`
+	QuoteCount     int64                    `json:"quote_count"`
+	Mentions       []MentionResponseBody    `json:"mentions"`
+	Media          []ChirpMediaResponseBody `json:"media"`
+	Poll           *PollResponseBody        `json:"poll,omitempty"`
+	Author         *ChirpAuthorResponseBody `json:"author,omitempty"`
+}
+
+// PollResponseBody leaves the vote counts out until the viewer has voted
+// or the poll has closed, so early results cannot sway anyone.
+type PollResponseBody struct {
+	ClosesAt      time.Time                `json:"closes_at"`
+	Closed        bool                     `json:"closed"`
+	TotalVotes    *int64                   `json:"total_votes,omitempty"`
+	VotedOptionID *uuid.UUID               `json:"voted_option_id,omitempty"`
+	Options       []PollOptionResponseBody `json:"options"`
+}
+
+type PollOptionResponseBody struct {
+	ID    uuid.UUID `json:"id"`
+	Label string    `json:"label"`
+	Votes *int64    `json:"votes,omitempty"`
+}
+
+type PollVoteRequestBody struct {
+	OptionID uuid.UUID `json:"option_id"`
+}
+
+type ChirpMediaResponseBody struct {
+	ID           uuid.UUID `json:"id"`
+	ContentType  string    `json:"content_type"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	Width        int32     `json:"width"`
+	Height       int32     `json:"height"`
+	AltText      string    `json:"alt_text"`
+}
+
+type ChirpAuthorResponseBody struct {
+	ID          uuid.UUID `json:"id"`
+	Handle      string    `json:"handle,omitempty"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url"`
+	IsChirpyRed bool      `json:"is_chirpy_red"`
+}
+
+type MentionResponseBody struct {
+	UserID uuid.UUID `json:"user_id"`
+	Handle string    `json:"handle"`
+	Start  int32     `json:"start"`
+	End    int32     `json:"end"`
+}
+
+type ChirpsPageResponseBody struct {
+	Chirps     []ChirpResponseBody `json:"chirps"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+type ChirpThreadResponseBody struct {
+	ChirpResponseBody
+	ReplyCount int                        `json:"reply_count"`
+	Replies    []*ChirpThreadResponseBody `json:"replies"`
+}
+
+type ChirpDeletedEventBody struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"user_id"`
+	Tombstoned bool      `json:"tombstoned"`
+}
+
+type ChirpRevisionResponseBody struct {
+	ID         uuid.UUID `json:"id"`
+	ChirpID    uuid.UUID `json:"chirp_id"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+	ReplacedAt time.Time `json:"replaced_at"`
+}
+
+type ChirpHistoryResponseBody struct {
+	Chirp     ChirpResponseBody           `json:"chirp"`
+	Revisions []ChirpRevisionResponseBody `json:"revisions"`
+}
+
+type TrendingHashtagResponseBody struct {
+	Tag        string `json:"tag"`
+	ChirpCount int64  `json:"chirp_count"`
+}
+
+type TrendingHashtagsResponseBody struct {
+	Window   string                        `json:"window"`
+	Hashtags []TrendingHashtagResponseBody `json:"hashtags"`
 }
 
 type UserRequestBody struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Handle   string `json:"handle"`
 }
 
 type UserResponseBody struct {
@@ -29,15 +154,254 @@ type UserResponseBody struct {
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 	Email          string    `json:"email"`
+	Handle         string    `json:"handle,omitempty"`
+	DisplayName    string    `json:"display_name"`
+	Bio            string    `json:"bio"`
+	AvatarURL      string    `json:"avatar_url"`
 	HashedPassword string    `json:"hashed_password"`
 	Token          string    `json:"token"`
 	RefreshToken   string    `json:"refresh_token"`
 	IsChirpyRed    bool      `json:"is_chirpy_red"`
 }
 
+type PublicUserResponseBody struct {
+	ID            uuid.UUID  `json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	Handle        string     `json:"handle,omitempty"`
+	DisplayName   string     `json:"display_name"`
+	Bio           string     `json:"bio"`
+	AvatarURL     string     `json:"avatar_url"`
+	IsChirpyRed   bool       `json:"is_chirpy_red"`
+	PinnedChirpID *uuid.UUID `json:"pinned_chirp_id,omitempty"`
+}
+
+type UserProfileResponseBody struct {
+	PublicUserResponseBody
+	FollowerCount  int64 `json:"follower_count"`
+	FollowingCount int64 `json:"following_count"`
+}
+
+// UserProfileRequestBody uses pointers so a PATCH only touches the fields
+// it sends. An empty handle clears it.
+type UserProfileRequestBody struct {
+	Handle      *string `json:"handle"`
+	DisplayName *string `json:"display_name"`
+	Bio         *string `json:"bio"`
+	AvatarURL   *string `json:"avatar_url"`
+}
+
+type FollowResponseBody struct {
+	PublicUserResponseBody
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+type FollowsPageResponseBody struct {
+	Users      []FollowResponseBody `json:"users"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+type NotificationResponseBody struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	Type      string     `json:"type"`
+	ActorID   *uuid.UUID `json:"actor_id,omitempty"`
+	ChirpID   *uuid.UUID `json:"chirp_id,omitempty"`
+	ReadAt    *time.Time `json:"read_at"`
+}
+
+type NotificationsPageResponseBody struct {
+	Notifications []NotificationResponseBody `json:"notifications"`
+	UnreadCount   int64                      `json:"unread_count"`
+	NextCursor    string                     `json:"next_cursor,omitempty"`
+}
+
+type NotificationsReadRequestBody struct {
+	IDs []uuid.UUID `json:"ids"`
+}
+
+type NotificationsReadResponseBody struct {
+	MarkedRead  int64 `json:"marked_read"`
+	UnreadCount int64 `json:"unread_count"`
+}
+
+type ConversationRequestBody struct {
+	ParticipantIDs []uuid.UUID `json:"participant_ids"`
+	Body           string      `json:"body"`
+}
+
+type ConversationParticipantResponseBody struct {
+	UserID     uuid.UUID  `json:"user_id"`
+	JoinedAt   time.Time  `json:"joined_at"`
+	LastReadAt *time.Time `json:"last_read_at"`
+}
+
+type ConversationResponseBody struct {
+	ID           uuid.UUID                             `json:"id"`
+	CreatedAt    time.Time                             `json:"created_at"`
+	UpdatedAt    time.Time                             `json:"updated_at"`
+	Participants []ConversationParticipantResponseBody `json:"participants"`
+	LastMessage  *DirectMessageResponseBody            `json:"last_message"`
+	UnreadCount  int64                                 `json:"unread_count"`
+}
+
+type ConversationsPageResponseBody struct {
+	Conversations []ConversationResponseBody `json:"conversations"`
+	NextCursor    string                     `json:"next_cursor,omitempty"`
+}
+
+type DirectMessageRequestBody struct {
+	Body string `json:"body"`
+}
+
+type DirectMessageResponseBody struct {
+	ID             uuid.UUID   `json:"id"`
+	CreatedAt      time.Time   `json:"created_at"`
+	ConversationID uuid.UUID   `json:"conversation_id"`
+	SenderID       uuid.UUID   `json:"sender_id"`
+	Body           string      `json:"body"`
+	ReadBy         []uuid.UUID `json:"read_by"`
+}
+
+type DirectMessagesPageResponseBody struct {
+	Messages   []DirectMessageResponseBody `json:"messages"`
+	NextCursor string                      `json:"next_cursor,omitempty"`
+}
+
+type WebsocketClientMessage struct {
+	Action  string `json:"action"`
+	Channel string `json:"channel,omitempty"`
+	Token   string `json:"token,omitempty"`
+}
+
+type WebsocketServerMessage struct {
+	Type    string          `json:"type"`
+	Channel string          `json:"channel,omitempty"`
+	Event   string          `json:"event,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
 type WebhookRequestBody struct {
 	Event string `json:"event"`
 	Data  struct {
 		UserID string `json:"user_id"`
 	} `json:"data"`
 }
+
+type MediaResponseBody struct {
+	ID           uuid.UUID `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UserID       uuid.UUID `json:"user_id"`
+	Purpose      string    `json:"purpose"`
+	ContentType  string    `json:"content_type"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	Width        int32     `json:"width"`
+	Height       int32     `json:"height"`
+	SizeBytes    int64     `json:"size_bytes"`
+}
+
+type ScheduledChirpResponseBody struct {
+	ID            uuid.UUID                `json:"id"`
+	CreatedAt     time.Time                `json:"created_at"`
+	UpdatedAt     time.Time                `json:"updated_at"`
+	Body          string                   `json:"body"`
+	UserID        uuid.UUID                `json:"user_id"`
+	InReplyTo     *uuid.UUID               `json:"in_reply_to,omitempty"`
+	QuoteOf       *uuid.UUID               `json:"quote_of,omitempty"`
+	Media         []ChirpMediaResponseBody `json:"media"`
+	PublishAt     time.Time                `json:"publish_at"`
+	FailedAt      *time.Time               `json:"failed_at,omitempty"`
+	FailureReason string                   `json:"failure_reason,omitempty"`
+}
+
+type ScheduledChirpsPageResponseBody struct {
+	ScheduledChirps []ScheduledChirpResponseBody `json:"scheduled_chirps"`
+	NextCursor      string                       `json:"next_cursor,omitempty"`
+}
+
+type DraftRequestBody struct {
+	Body      string     `json:"body"`
+	InReplyTo *uuid.UUID `json:"in_reply_to"`
+	QuoteOf   *uuid.UUID `json:"quote_of"`
+}
+
+type DraftResponseBody struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Body      string     `json:"body"`
+	InReplyTo *uuid.UUID `json:"in_reply_to,omitempty"`
+	QuoteOf   *uuid.UUID `json:"quote_of,omitempty"`
+}
+
+type DraftsPageResponseBody struct {
+	Drafts     []DraftResponseBody `json:"drafts"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+type TrashedChirpResponseBody struct {
+	ChirpResponseBody
+	DeletedAt       time.Time `json:"deleted_at"`
+	RestorableUntil time.Time `json:"restorable_until"`
+}
+
+type TrashPageResponseBody struct {
+	Chirps     []TrashedChirpResponseBody `json:"chirps"`
+	NextCursor string                     `json:"next_cursor,omitempty"`
+}
+
+type ReportRequestBody struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details"`
+}
+
+type ReportResponseBody struct {
+	ID         uuid.UUID          `json:"id"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+	ChirpID    uuid.UUID          `json:"chirp_id"`
+	ReporterID uuid.UUID          `json:"reporter_id"`
+	Reason     string             `json:"reason"`
+	Details    string             `json:"details"`
+	Status     string             `json:"status"`
+	HandledBy  *uuid.UUID         `json:"handled_by,omitempty"`
+	Chirp      *ChirpResponseBody `json:"chirp,omitempty"`
+}
+
+type ReportsPageResponseBody struct {
+	Reports    []ReportResponseBody `json:"reports"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+type ReportStatusRequestBody struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+// ModerationActionRequestBody is shared by every admin action. Reason is
+// shown to suspended or banned users, while Note stays in the audit log.
+type ModerationActionRequestBody struct {
+	Note      string     `json:"note"`
+	ReportID  *uuid.UUID `json:"report_id"`
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type ModerationActionResponseBody struct {
+	ID           uuid.UUID  `json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	AdminID      *uuid.UUID `json:"admin_id,omitempty"`
+	Action       string     `json:"action"`
+	ReportID     *uuid.UUID `json:"report_id,omitempty"`
+	ChirpID      *uuid.UUID `json:"chirp_id,omitempty"`
+	TargetUserID *uuid.UUID `json:"target_user_id,omitempty"`
+	Note         string     `json:"note"`
+	Reason       string     `json:"reason,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+}
+
+type ModerationActionsPageResponseBody struct {
+	Actions    []ModerationActionResponseBody `json:"actions"`
+	NextCursor string                         `json:"next_cursor,omitempty"`
+}